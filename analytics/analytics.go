@@ -0,0 +1,249 @@
+// Package analytics computes productivity statistics from the record of what's been done: when
+// nodes were pushed and archived, and how deep the top frame of each list has been over time.
+//
+// The server extracts that record from its history file and timelog; this package doesn't know
+// about either format.
+package analytics
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Completion records that a node was archived.
+type Completion struct {
+	ListName string
+	Time     time.Time
+	// Pushed is when the node was pushed onto the list, or the zero time if that isn't known.
+	Pushed time.Time
+}
+
+// DepthSample records that, as of Time, the top frame of the list identified by ListName was Depth
+// levels deep: 1 for a task with no subtasks, 2 for its subtask, and so on. Depth is 0 if the list
+// had no top frame.
+type DepthSample struct {
+	ListName string
+	Time     time.Time
+	Depth    int
+}
+
+// Day summarizes a single day.
+type Day struct {
+	// Date is midnight at the start of the day, in the local time zone.
+	Date        time.Time
+	Completions int
+	// Samples is the number of times the top frame of a list changed during the day. MaxDepth and
+	// AvgDepth are the maximum and mean depths of the lists' top frames during the day, the mean
+	// weighted by how long each frame was on top (see Compute), or zero if no depth is known.
+	Samples  int
+	MaxDepth int
+	AvgDepth float64
+}
+
+// Week summarizes a week, starting on a Monday.
+type Week struct {
+	// Start is midnight at the start of the week's Monday, in the local time zone.
+	Start       time.Time
+	Completions int
+}
+
+// List summarizes a list.
+type List struct {
+	// ListName is empty for completions whose list isn't known.
+	ListName    string
+	Completions int
+	// AvgTimeToArchive is the mean time from push to archive of the list's completions whose push
+	// time is known, or zero if there are none.
+	AvgTimeToArchive time.Duration
+}
+
+// Stats are the statistics for a period of time.
+type Stats struct {
+	// Days has an entry for every day in the period, in chronological order, and Weeks has an entry
+	// for every week that overlaps the period.
+	Days  []Day
+	Weeks []Week
+	// Lists has an entry for every list with a completion in the period, in lexical order by name.
+	Lists []List
+
+	Completions      int
+	AvgTimeToArchive time.Duration
+	MaxDepth         int
+	AvgDepth         float64
+}
+
+// startOfDay returns midnight at the start of t's day, in the local time zone.
+func startOfDay(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// startOfWeek returns midnight at the start of the Monday of t's week, in the local time zone.
+func startOfWeek(t time.Time) time.Time {
+	d := startOfDay(t)
+	// Weekday counts from Sunday; we count from Monday.
+	return d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7))
+}
+
+// durationMean is a running mean of durations.
+type durationMean struct {
+	total time.Duration
+	n     int
+}
+
+func (m *durationMean) add(d time.Duration) {
+	m.total += d
+	m.n++
+}
+
+func (m *durationMean) mean() time.Duration {
+	if m.n == 0 {
+		return 0
+	}
+	return m.total / time.Duration(m.n)
+}
+
+// Compute computes the statistics for the period from the start of from's day through the end of
+// to's day. Completions and samples outside the period are ignored.
+//
+// Each sample lasts until the next sample for the same list, or until to, and is weighted by that
+// time in the mean depths. A sample that lasts past midnight counts toward each day it spans.
+func Compute(completions []Completion, samples []DepthSample, from, to time.Time) *Stats {
+	stats := &Stats{
+		Days:  make([]Day, 0),
+		Weeks: make([]Week, 0),
+		Lists: make([]List, 0),
+	}
+	first := startOfDay(from)
+	last := startOfDay(to)
+	if last.Before(first) {
+		return stats
+	}
+
+	days := make(map[time.Time]int)
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		days[d] = len(stats.Days)
+		stats.Days = append(stats.Days, Day{Date: d})
+	}
+	weeks := make(map[time.Time]int)
+	for w := startOfWeek(first); !w.After(last); w = w.AddDate(0, 0, 7) {
+		weeks[w] = len(stats.Weeks)
+		stats.Weeks = append(stats.Weeks, Week{Start: w})
+	}
+
+	lists := make(map[string]*List)
+	listMeans := make(map[string]*durationMean)
+	var overallMean durationMean
+	for _, c := range completions {
+		i, ok := days[startOfDay(c.Time)]
+		if !ok {
+			continue
+		}
+		stats.Days[i].Completions++
+		stats.Weeks[weeks[startOfWeek(c.Time)]].Completions++
+		stats.Completions++
+
+		if lists[c.ListName] == nil {
+			lists[c.ListName] = &List{ListName: c.ListName}
+			listMeans[c.ListName] = new(durationMean)
+		}
+		lists[c.ListName].Completions++
+		if !c.Pushed.IsZero() && !c.Pushed.After(c.Time) {
+			listMeans[c.ListName].add(c.Time.Sub(c.Pushed))
+			overallMean.add(c.Time.Sub(c.Pushed))
+		}
+	}
+	for name, l := range lists {
+		l.AvgTimeToArchive = listMeans[name].mean()
+		stats.Lists = append(stats.Lists, *l)
+	}
+	sort.Slice(stats.Lists, func(i, j int) bool { return stats.Lists[i].ListName < stats.Lists[j].ListName })
+	stats.AvgTimeToArchive = overallMean.mean()
+
+	sorted := append([]DepthSample{}, samples...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+	// ends holds the time at which each sample stops applying.
+	ends := make([]time.Time, len(sorted))
+	next := make(map[string]time.Time)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		end, ok := next[sorted[i].ListName]
+		if !ok || end.After(to) {
+			end = to
+		}
+		ends[i] = end
+		next[sorted[i].ListName] = sorted[i].Time
+	}
+
+	// depthSeconds holds, for each day, the depths multiplied by the number of seconds they lasted,
+	// and seconds the total number of seconds.
+	depthSeconds := make([]float64, len(stats.Days))
+	seconds := make([]float64, len(stats.Days))
+	for i, s := range sorted {
+		j, ok := days[startOfDay(s.Time)]
+		if !ok {
+			continue
+		}
+		stats.Days[j].Samples++
+		if s.Depth > stats.Days[j].MaxDepth {
+			stats.Days[j].MaxDepth = s.Depth
+		}
+		for start := s.Time; start.Before(ends[i]); {
+			d := startOfDay(start)
+			j, ok := days[d]
+			if !ok {
+				break
+			}
+			end := d.AddDate(0, 0, 1)
+			if end.After(ends[i]) {
+				end = ends[i]
+			}
+			depthSeconds[j] += float64(s.Depth) * end.Sub(start).Seconds()
+			seconds[j] += end.Sub(start).Seconds()
+			if s.Depth > stats.Days[j].MaxDepth {
+				stats.Days[j].MaxDepth = s.Depth
+			}
+			start = end
+		}
+	}
+	totalDepthSeconds, totalSeconds := 0.0, 0.0
+	for i := range stats.Days {
+		if seconds[i] > 0 {
+			stats.Days[i].AvgDepth = depthSeconds[i] / seconds[i]
+		}
+		if stats.Days[i].MaxDepth > stats.MaxDepth {
+			stats.MaxDepth = stats.Days[i].MaxDepth
+		}
+		totalDepthSeconds += depthSeconds[i]
+		totalSeconds += seconds[i]
+	}
+	if totalSeconds > 0 {
+		stats.AvgDepth = totalDepthSeconds / totalSeconds
+	}
+
+	return stats
+}
+
+// sparks are the characters that make up a sparkline, from lowest to highest.
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline returns a line of text with a character for each of values, whose height is
+// proportional to the value. Negative values are drawn as zero.
+func Sparkline(values []float64) string {
+	max := 0.0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if max > 0 && v > 0 {
+			i = int(v / max * float64(len(sparks)-1))
+		}
+		b.WriteRune(sparks[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// at returns the given time on the given day of October 2026, in the local time zone.
+func at(day, hour int) time.Time {
+	return time.Date(2026, time.October, day, hour, 0, 0, 0, time.Local)
+}
+
+func TestCompute(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	completions := []Completion{
+		{ListName: "make_pasta", Time: at(12, 9), Pushed: at(12, 8)},
+		{ListName: "make_pasta", Time: at(12, 10), Pushed: at(12, 7)},
+		{ListName: "chores", Time: at(14, 9)},
+		// Outside the period
+		{ListName: "chores", Time: at(1, 9)},
+	}
+	// Each sample lasts until the next one for the same list, or until the end of the period.
+	samples := []DepthSample{
+		{ListName: "chores", Time: at(13, 9), Depth: 1},
+		{ListName: "make_pasta", Time: at(12, 8), Depth: 3},
+		{ListName: "make_pasta", Time: at(12, 9), Depth: 2},
+		{ListName: "make_pasta", Time: at(12, 12), Depth: 0},
+	}
+	// Monday, October 12 through Tuesday, October 20
+	stats := Compute(completions, samples, at(12, 0), at(20, 23))
+
+	assert.Equal(9, len(stats.Days))
+	// depth 3 for an hour, 2 for 3 hours, and 0 for the last 12 hours of the day
+	assert.Equal(Day{Date: at(12, 0), Completions: 2, Samples: 3, MaxDepth: 3, AvgDepth: 9.0 / 16}, stats.Days[0])
+	// make_pasta at depth 0 all day, and chores at depth 1 for the last 15 hours
+	assert.Equal(Day{Date: at(13, 0), Samples: 1, MaxDepth: 1, AvgDepth: 15.0 / 39}, stats.Days[1])
+	// both lists carry on from the day before
+	assert.Equal(Day{Date: at(14, 0), Completions: 1, MaxDepth: 1, AvgDepth: 0.5}, stats.Days[2])
+
+	assert.Equal([]Week{{Start: at(12, 0), Completions: 3}, {Start: at(19, 0)}}, stats.Weeks)
+	assert.Equal([]List{
+		{ListName: "chores", Completions: 1},
+		{ListName: "make_pasta", Completions: 2, AvgTimeToArchive: 2 * time.Hour},
+	}, stats.Lists)
+
+	assert.Equal(3, stats.Completions)
+	assert.Equal(2*time.Hour, stats.AvgTimeToArchive)
+	assert.Equal(3, stats.MaxDepth)
+	// make_pasta from the 12th at 8:00 through the 20th at 23:00 is 207 hours, 5 of them deeper than
+	// 0; chores from the 13th at 9:00 is 182 hours, all at depth 1.
+	assert.InDelta(float64(3+2*3+182)/float64(207+182), stats.AvgDepth, 1e-9)
+}
+
+func TestCompute_Empty(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	stats := Compute(nil, nil, at(12, 0), at(12, 23))
+	assert.Equal([]Day{{Date: at(12, 0)}}, stats.Days)
+	assert.Equal(0, len(stats.Lists))
+	assert.Equal(time.Duration(0), stats.AvgTimeToArchive)
+}
+
+func TestSparkline(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	assert.Equal("▁▄█▁", Sparkline([]float64{0, 3.5, 7, -1}))
+	assert.Equal("▁▁", Sparkline([]float64{0, 0}))
+	assert.Equal("", Sparkline(nil))
+}
@@ -0,0 +1,103 @@
+package analytics
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// Interrupt records that a node was pushed on top of a frame that it had nothing to do with, as
+// opposed to being pushed as a planned substep of the frame.
+type Interrupt struct {
+	ListName string
+	// Interrupted is the path (a node's referent preceded by those of its ancestors) to the frame
+	// that was on top when the interrupt was pushed, and Interrupting is the path to the node that
+	// was pushed.
+	Interrupted  []string
+	Interrupting []string
+	Start        time.Time
+	// End is when the interrupting node and its descendants stopped being on top of the list, or the
+	// zero time if they're still on top.
+	End time.Time
+}
+
+// Duration returns how long i lasted, as of now.
+func (i Interrupt) Duration(now time.Time) time.Duration {
+	if i.End.IsZero() {
+		return now.Sub(i.Start)
+	}
+	return i.End.Sub(i.Start)
+}
+
+// Kind returns a description of what sort of thing i was: the interrupting node's first tag (e.g.
+// `#social`) if it has one, or else its text.
+func (i Interrupt) Kind() string {
+	if len(i.Interrupting) == 0 {
+		return ""
+	}
+	md, text := common.ParseMetadata(i.Interrupting[len(i.Interrupting)-1])
+	if len(md.Tags) > 0 {
+		return "#" + md.Tags[0]
+	}
+	return text
+}
+
+// KindCount is the number of interrupts of a given kind (see Interrupt.Kind).
+type KindCount struct {
+	Kind  string
+	Count int
+}
+
+// InterruptSummary summarizes the interrupts of a single frame.
+type InterruptSummary struct {
+	ListName string
+	Frame    []string
+	Count    int
+	// Duration is the total time the frame spent interrupted.
+	Duration time.Duration
+	// Kinds has an entry for each kind of interrupt, most frequent first.
+	Kinds []KindCount
+}
+
+// SummarizeInterrupts summarizes interrupts by the frame interrupted, as of now. The most
+// frequently interrupted frames come first.
+func SummarizeInterrupts(interrupts []Interrupt, now time.Time) []InterruptSummary {
+	byFrame := make(map[string]*InterruptSummary)
+	kinds := make(map[string]map[string]int)
+	for _, i := range interrupts {
+		k := i.ListName + "\t" + strings.Join(i.Interrupted, "\t")
+		if byFrame[k] == nil {
+			byFrame[k] = &InterruptSummary{ListName: i.ListName, Frame: i.Interrupted}
+			kinds[k] = make(map[string]int)
+		}
+		byFrame[k].Count++
+		byFrame[k].Duration += i.Duration(now)
+		kinds[k][i.Kind()]++
+	}
+
+	rslt := make([]InterruptSummary, 0, len(byFrame))
+	for k, s := range byFrame {
+		for kind, n := range kinds[k] {
+			s.Kinds = append(s.Kinds, KindCount{Kind: kind, Count: n})
+		}
+		sort.Slice(s.Kinds, func(i, j int) bool {
+			if s.Kinds[i].Count != s.Kinds[j].Count {
+				return s.Kinds[i].Count > s.Kinds[j].Count
+			}
+			return s.Kinds[i].Kind < s.Kinds[j].Kind
+		})
+		rslt = append(rslt, *s)
+	}
+	sort.Slice(rslt, func(i, j int) bool {
+		if rslt[i].Count != rslt[j].Count {
+			return rslt[i].Count > rslt[j].Count
+		}
+		if rslt[i].ListName != rslt[j].ListName {
+			return rslt[i].ListName < rslt[j].ListName
+		}
+		return strings.Join(rslt[i].Frame, "\t") < strings.Join(rslt[j].Frame, "\t")
+	})
+	return rslt
+}
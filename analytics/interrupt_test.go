@@ -0,0 +1,50 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterrupt_Kind(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	i := Interrupt{Interrupting: []string{"check Twitter #social #phone"}}
+	assert.Equal("#social", i.Kind())
+	i = Interrupt{Interrupting: []string{"errands", "text Mom ~5m"}}
+	assert.Equal("text Mom", i.Kind())
+}
+
+func TestSummarizeInterrupts(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	boil := []string{"cook pasta", "wait for water to boil"}
+	interrupts := []Interrupt{
+		{ListName: "dinner", Interrupted: boil, Interrupting: []string{"check Twitter #social"}, Start: at(12, 8), End: at(12, 9)},
+		{ListName: "dinner", Interrupted: []string{"cook pasta"}, Interrupting: []string{"text Mom"}, Start: at(12, 9), End: at(12, 10)},
+		{ListName: "dinner", Interrupted: boil, Interrupting: []string{"text Mom"}, Start: at(12, 10), End: at(12, 11)},
+		// Still going
+		{ListName: "dinner", Interrupted: boil, Interrupting: []string{"read news #social"}, Start: at(12, 11)},
+	}
+
+	summaries := SummarizeInterrupts(interrupts, at(12, 14))
+	assert.Equal([]InterruptSummary{
+		{
+			ListName: "dinner",
+			Frame:    boil,
+			Count:    3,
+			Duration: 5 * time.Hour,
+			Kinds:    []KindCount{{Kind: "#social", Count: 2}, {Kind: "text Mom", Count: 1}},
+		},
+		{
+			ListName: "dinner",
+			Frame:    []string{"cook pasta"},
+			Count:    1,
+			Duration: time.Hour,
+			Kinds:    []KindCount{{Kind: "text Mom", Count: 1}},
+		},
+	}, summaries)
+}
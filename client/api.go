@@ -1,12 +1,10 @@
 package client
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
-	"net/url"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"sync"
+	"time"
 
 	"github.com/danslimmon/impulse/common"
 	"github.com/danslimmon/impulse/server"
@@ -17,117 +15,512 @@ type Client struct {
 	addr string
 }
 
-// url returns the full URL to the Impulse API endpoint with the given path.
-func (apiClient *Client) url(path string) string {
-	u := url.URL{
-		Scheme: "http",
-		Host:   apiClient.addr,
-		Path:   path,
+// GetTaskList returns the tasks in the given list. If there's no such list, the error is a
+// server.NoSuchListError.
+func (apiClient *Client) GetTaskList(listName string) (*server.GetTaskListResponse, error) {
+	reqObj := &server.GetTaskListRequest{ListName: listName}
+	respObj := new(server.GetTaskListResponse)
+	if err := apiClient.call("GetTaskList", reqObj, respObj); err != nil {
+		return nil, err
 	}
-	return u.String()
+	return respObj, nil
 }
 
-func (apiClient *Client) GetTaskList(listName string) (*server.GetTaskListResponse, error) {
-	path := fmt.Sprintf("/tasklist/%s", listName)
-	resp, err := http.Get(apiClient.url(path))
-	if err != nil {
+// ArchiveLine archives the line with the given ID.
+func (apiClient *Client) ArchiveLine(lineId common.LineID) (*server.ArchiveLineResponse, error) {
+	reqObj := &server.ArchiveLineRequest{LineID: lineId}
+	respObj := new(server.ArchiveLineResponse)
+	if err := apiClient.call("ArchiveLine", reqObj, respObj); err != nil {
 		return nil, err
 	}
+	return respObj, nil
+}
 
-	b, err := ioutil.ReadAll(resp.Body)
-	resp.Body.Close()
-	if err != nil {
+// ArchiveSubtree archives the line with the given ID along with all its descendants.
+func (apiClient *Client) ArchiveSubtree(lineId common.LineID) (*server.ArchiveSubtreeResponse, error) {
+	reqObj := &server.ArchiveSubtreeRequest{LineID: lineId}
+	respObj := new(server.ArchiveSubtreeResponse)
+	if err := apiClient.call("ArchiveSubtree", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// InsertTask inserts task after the line with the given ID, or at the top of the list if the ID is
+// of the form `<list>:0`.
+func (apiClient *Client) InsertTask(lineId common.LineID, task *common.Task) (*server.InsertTaskResponse, error) {
+	reqObj := &server.InsertTaskRequest{LineID: lineId, Task: task}
+	respObj := new(server.InsertTaskResponse)
+	if err := apiClient.call("InsertTask", reqObj, respObj); err != nil {
 		return nil, err
 	}
+	return respObj, nil
+}
 
-	respObj := new(server.GetTaskListResponse)
-	err = json.Unmarshal(b, respObj)
+// dial opens a connection to the Impulse RPC API.
+func (apiClient *Client) dial() (*rpc.Client, error) {
+	return jsonrpc.Dial("tcp", apiClient.addr)
+}
+
+// call invokes the named method of the Impulse RPC API.
+//
+// Typed errors returned by the server (e.g. server.IndentError) are returned as such, so callers
+// can inspect them with errors.Is and errors.As.
+func (apiClient *Client) call(method string, req, resp interface{}) error {
+	conn, err := apiClient.dial()
 	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return server.DecodeError(conn.Call("Server."+method, req, resp))
+}
+
+// CreateList creates a new, empty list with the given name.
+func (apiClient *Client) CreateList(name string) (*server.CreateListResponse, error) {
+	reqObj := &server.CreateListRequest{ListName: name}
+	respObj := new(server.CreateListResponse)
+	if err := apiClient.call("CreateList", reqObj, respObj); err != nil {
 		return nil, err
 	}
-	if respObj.Error != "" {
-		return nil, fmt.Errorf("Error response from server: %s", respObj.Error)
+	return respObj, nil
+}
+
+// GetNote returns the note attached to the node with the given line ID.
+func (apiClient *Client) GetNote(lineId common.LineID) (*server.GetNoteResponse, error) {
+	reqObj := &server.GetNoteRequest{LineID: lineId}
+	respObj := new(server.GetNoteResponse)
+	if err := apiClient.call("GetNote", reqObj, respObj); err != nil {
+		return nil, err
 	}
+	return respObj, nil
+}
 
-	if resp.StatusCode == 200 {
-		return respObj, nil
-	} else {
-		fmt.Printf("DEBUG: server response: '%s'", string(b))
-		return respObj, fmt.Errorf("error: response code; body in logs")
+// SetNote replaces the note attached to the node with the given line ID. An empty note removes it.
+func (apiClient *Client) SetNote(lineId common.LineID, note string) (*server.SetNoteResponse, error) {
+	reqObj := &server.SetNoteRequest{LineID: lineId, Note: note}
+	respObj := new(server.SetNoteResponse)
+	if err := apiClient.call("SetNote", reqObj, respObj); err != nil {
+		return nil, err
 	}
+	return respObj, nil
 }
 
-func (apiClient *Client) ArchiveLine(lineId common.LineID) (*server.ArchiveLineResponse, error) {
-	path := fmt.Sprintf("/archive_line/%s", url.PathEscape(string(lineId)))
-	resp, err := http.Get(apiClient.url(path))
-	if err != nil {
+// ResolveLine returns the ID of the line in the given list whose text, as it appears in the list's
+// data, is text. Line text typed or pasted by the user should be resolved this way, rather than
+// passed to common.GetLineID, since the list may not be indented with tabs.
+func (apiClient *Client) ResolveLine(listName, text string) (*server.ResolveLineResponse, error) {
+	reqObj := &server.ResolveLineRequest{ListName: listName, Text: text}
+	respObj := new(server.ResolveLineResponse)
+	if err := apiClient.call("ResolveLine", reqObj, respObj); err != nil {
 		return nil, err
 	}
+	return respObj, nil
+}
 
-	b, err := ioutil.ReadAll(resp.Body)
-	resp.Body.Close()
-	if err != nil {
+// MoveToList moves the node with the given line ID, along with its descendants, to dest, a position
+// interpreted as by InsertTask.
+func (apiClient *Client) MoveToList(lineId, dest common.LineID) (*server.MoveToListResponse, error) {
+	reqObj := &server.MoveToListRequest{LineID: lineId, Dest: dest}
+	respObj := new(server.MoveToListResponse)
+	if err := apiClient.call("MoveToList", reqObj, respObj); err != nil {
 		return nil, err
 	}
+	return respObj, nil
+}
 
-	respObj := new(server.ArchiveLineResponse)
-	err = json.Unmarshal(b, respObj)
-	if err != nil {
+// GetActiveList returns the name of the active list, or the empty string if there is none.
+func (apiClient *Client) GetActiveList() (*server.GetActiveListResponse, error) {
+	reqObj := &server.GetActiveListRequest{}
+	respObj := new(server.GetActiveListResponse)
+	if err := apiClient.call("GetActiveList", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// SetActiveList makes the list with the given name the active list.
+func (apiClient *Client) SetActiveList(name string) (*server.SetActiveListResponse, error) {
+	reqObj := &server.SetActiveListRequest{ListName: name}
+	respObj := new(server.SetActiveListResponse)
+	if err := apiClient.call("SetActiveList", reqObj, respObj); err != nil {
 		return nil, err
 	}
-	if respObj.Error != "" {
-		return nil, fmt.Errorf("Error response from server: %s", respObj.Error)
+	return respObj, nil
+}
+
+// GetStacks returns a summary of each task list, including its top frame.
+func (apiClient *Client) GetStacks() (*server.GetStacksResponse, error) {
+	reqObj := &server.GetStacksRequest{}
+	respObj := new(server.GetStacksResponse)
+	if err := apiClient.call("GetStacks", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// GetHoist returns the hoist belonging to the client named clientName, along with the hoisted
+// subtree. If there's no hoist, the response's Hoist is nil.
+func (apiClient *Client) GetHoist(clientName string) (*server.GetHoistResponse, error) {
+	reqObj := &server.GetHoistRequest{Client: clientName}
+	respObj := new(server.GetHoistResponse)
+	if err := apiClient.call("GetHoist", reqObj, respObj); err != nil {
+		return nil, err
 	}
+	return respObj, nil
+}
 
-	if resp.StatusCode == 200 {
-		return respObj, nil
-	} else {
-		fmt.Printf("DEBUG: server response: '%s'", string(b))
-		return respObj, fmt.Errorf("error: response code; body in logs")
+// SetHoist hoists the node with the given line ID for the client named clientName.
+func (apiClient *Client) SetHoist(clientName string, lineId common.LineID) (*server.SetHoistResponse, error) {
+	reqObj := &server.SetHoistRequest{Client: clientName, LineID: lineId}
+	respObj := new(server.SetHoistResponse)
+	if err := apiClient.call("SetHoist", reqObj, respObj); err != nil {
+		return nil, err
 	}
+	return respObj, nil
 }
 
-func (apiClient *Client) InsertTask(lineId common.LineID, task *common.Task) (*server.InsertTaskResponse, error) {
-	reqObj := &server.InsertTaskRequest{
+// Unhoist removes the hoist belonging to the client named clientName.
+func (apiClient *Client) Unhoist(clientName string) (*server.UnhoistResponse, error) {
+	reqObj := &server.UnhoistRequest{Client: clientName}
+	respObj := new(server.UnhoistResponse)
+	if err := apiClient.call("Unhoist", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// Push puts task on top of the given list, or on top of the hoisted subtree if the client named
+// clientName has hoisted a node in the list.
+func (apiClient *Client) Push(clientName, listName string, task *common.Task) (*server.PushResponse, error) {
+	reqObj := &server.PushRequest{Client: clientName, ListName: listName, Task: task}
+	respObj := new(server.PushResponse)
+	if err := apiClient.call("Push", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// Pop archives the top frame of the given list, scoped to the hoist of the client named clientName.
+func (apiClient *Client) Pop(clientName, listName string) (*server.PopResponse, error) {
+	reqObj := &server.PopRequest{Client: clientName, ListName: listName}
+	respObj := new(server.PopResponse)
+	if err := apiClient.call("Pop", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// GetStaleNodes returns the nodes in the given list (or in all lists, if listName is empty) that
+// haven't been touched for at least age.
+func (apiClient *Client) GetStaleNodes(listName string, age time.Duration) (*server.GetStaleNodesResponse, error) {
+	reqObj := &server.GetStaleNodesRequest{ListName: listName, Age: age}
+	respObj := new(server.GetStaleNodesResponse)
+	if err := apiClient.call("GetStaleNodes", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// Touch marks the node with the given line ID as touched now.
+func (apiClient *Client) Touch(lineId common.LineID) (*server.TouchResponse, error) {
+	reqObj := &server.TouchRequest{LineID: lineId}
+	respObj := new(server.TouchResponse)
+	if err := apiClient.call("Touch", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// Unblock marks the line with the given ID as no longer blocked.
+func (apiClient *Client) Unblock(lineId common.LineID) (*server.UnblockResponse, error) {
+	reqObj := &server.UnblockRequest{LineID: lineId}
+	respObj := new(server.UnblockResponse)
+	if err := apiClient.call("Unblock", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// SetMetadata replaces the metadata of the line with the given ID.
+//
+// The line's new ID is returned in the response's LineID attribute.
+func (apiClient *Client) SetMetadata(lineId common.LineID, md common.Metadata) (*server.SetMetadataResponse, error) {
+	reqObj := &server.SetMetadataRequest{
+		LineID:   lineId,
+		Metadata: md,
+	}
+	respObj := new(server.SetMetadataResponse)
+	if err := apiClient.call("SetMetadata", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// GetTimeReport reports the time spent on each node, task, or day, according to by. See
+// server.GetTimeReport.
+func (apiClient *Client) GetTimeReport(by string) (*server.GetTimeReportResponse, error) {
+	reqObj := &server.GetTimeReportRequest{By: by}
+	respObj := new(server.GetTimeReportResponse)
+	if err := apiClient.call("GetTimeReport", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// GetStats computes productivity statistics for the given number of days, up to and including
+// today.
+func (apiClient *Client) GetStats(days int) (*server.GetStatsResponse, error) {
+	reqObj := &server.GetStatsRequest{Days: days}
+	respObj := new(server.GetStatsResponse)
+	if err := apiClient.call("GetStats", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// GetInterruptReport reports how often, for how long, and by what each frame has been interrupted.
+func (apiClient *Client) GetInterruptReport() (*server.GetInterruptReportResponse, error) {
+	reqObj := &server.GetInterruptReportRequest{}
+	respObj := new(server.GetInterruptReportResponse)
+	if err := apiClient.call("GetInterruptReport", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// StartFocus starts a focus session of duration d on the top frame of the list with the given name.
+func (apiClient *Client) StartFocus(listName string, d time.Duration) (*server.StartFocusResponse, error) {
+	reqObj := &server.StartFocusRequest{
+		ListName: listName,
+		Duration: d,
+	}
+	respObj := new(server.StartFocusResponse)
+	if err := apiClient.call("StartFocus", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// GetFocus returns the focus session in progress, if any.
+func (apiClient *Client) GetFocus() (*server.GetFocusResponse, error) {
+	respObj := new(server.GetFocusResponse)
+	if err := apiClient.call("GetFocus", &server.GetFocusRequest{}, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// StopFocus abandons the focus session in progress.
+func (apiClient *Client) StopFocus() (*server.StopFocusResponse, error) {
+	respObj := new(server.StopFocusResponse)
+	if err := apiClient.call("StopFocus", &server.StopFocusRequest{}, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// Snooze hides the line with the given ID from the stack until the given time.
+//
+// The line's new ID is returned in the response's LineID attribute.
+func (apiClient *Client) Snooze(lineId common.LineID, until time.Time) (*server.SnoozeResponse, error) {
+	reqObj := &server.SnoozeRequest{
 		LineID: lineId,
-		Task:   task,
+		Until:  until,
 	}
-	reqB, err := json.Marshal(reqObj)
-	if err != nil {
-		return nil, fmt.Errorf("Failed to marshal request object: %s", err.Error())
+	respObj := new(server.SnoozeResponse)
+	if err := apiClient.call("Snooze", reqObj, respObj); err != nil {
+		return nil, err
 	}
+	return respObj, nil
+}
 
-	path := "/insert_task/"
-	resp, err := http.Post(
-		apiClient.url(path),
-		"application/json",
-		bytes.NewReader(reqB),
-	)
-	if err != nil {
+// AddRecurrence adds a recurrence that inserts template into the list with the given name
+// according to the given schedule (see common.Schedule).
+func (apiClient *Client) AddRecurrence(listName, schedule string, template *common.Task) (*server.AddRecurrenceResponse, error) {
+	reqObj := &server.AddRecurrenceRequest{
+		ListName: listName,
+		Schedule: schedule,
+		Template: template,
+	}
+	respObj := new(server.AddRecurrenceResponse)
+	if err := apiClient.call("AddRecurrence", reqObj, respObj); err != nil {
 		return nil, err
 	}
+	return respObj, nil
+}
 
-	respB, err := ioutil.ReadAll(resp.Body)
-	resp.Body.Close()
-	if err != nil {
+// GetRecurrences returns the recurrences for the list with the given name. If listName is empty,
+// the recurrences for all lists are returned.
+func (apiClient *Client) GetRecurrences(listName string) (*server.GetRecurrencesResponse, error) {
+	reqObj := &server.GetRecurrencesRequest{ListName: listName}
+	respObj := new(server.GetRecurrencesResponse)
+	if err := apiClient.call("GetRecurrences", reqObj, respObj); err != nil {
 		return nil, err
 	}
+	return respObj, nil
+}
 
-	respObj := new(server.InsertTaskResponse)
-	err = json.Unmarshal(respB, respObj)
-	if err != nil {
+// SetRecurrencePaused pauses or resumes the recurrence with the given ID.
+func (apiClient *Client) SetRecurrencePaused(id string, paused bool) (*server.SetRecurrencePausedResponse, error) {
+	reqObj := &server.SetRecurrencePausedRequest{
+		ID:     id,
+		Paused: paused,
+	}
+	respObj := new(server.SetRecurrencePausedResponse)
+	if err := apiClient.call("SetRecurrencePaused", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// DeleteRecurrence deletes the recurrence with the given ID.
+func (apiClient *Client) DeleteRecurrence(id string) (*server.DeleteRecurrenceResponse, error) {
+	reqObj := &server.DeleteRecurrenceRequest{ID: id}
+	respObj := new(server.DeleteRecurrenceResponse)
+	if err := apiClient.call("DeleteRecurrence", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// Search returns the nodes in all lists that match query in the given mode (see
+// server.SearchSubstring, etc.). If history is true, the history file is searched too.
+func (apiClient *Client) Search(query, mode string, history bool) (*server.SearchResponse, error) {
+	reqObj := &server.SearchRequest{
+		Query:   query,
+		Mode:    mode,
+		History: history,
+	}
+	respObj := new(server.SearchResponse)
+	if err := apiClient.call("Search", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// Query returns the nodes that match query (see common.Query) in the list with the given name. If
+// listName is empty, all lists are queried.
+func (apiClient *Client) Query(listName, query string) (*server.QueryResponse, error) {
+	reqObj := &server.QueryRequest{
+		ListName: listName,
+		Query:    query,
+	}
+	respObj := new(server.QueryResponse)
+	if err := apiClient.call("Query", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// Export returns the representation of the list with the given name in the given format (see
+// exporter.Formats).
+func (apiClient *Client) Export(listName, format string) (*server.ExportResponse, error) {
+	reqObj := &server.ExportRequest{
+		ListName: listName,
+		Format:   format,
+	}
+	respObj := new(server.ExportResponse)
+	if err := apiClient.call("Export", reqObj, respObj); err != nil {
 		return nil, err
 	}
-	if respObj.Error != "" {
-		return nil, fmt.Errorf("Error response from server: %s", respObj.Error)
+	return respObj, nil
+}
+
+// Import parses data, a task list in the given format (see importer.Formats), and adds the tasks
+// in it to the end of the list with the given name. If there's no such list, it's created.
+func (apiClient *Client) Import(listName, format, data string) (*server.ImportResponse, error) {
+	reqObj := &server.ImportRequest{
+		ListName: listName,
+		Format:   format,
+		Data:     data,
 	}
+	respObj := new(server.ImportResponse)
+	if err := apiClient.call("Import", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// CheckList reports every problem with the data of the list with the given name, along with the
+// repairs RepairList would make.
+func (apiClient *Client) CheckList(listName string) (*server.CheckListResponse, error) {
+	reqObj := &server.CheckListRequest{ListName: listName}
+	respObj := new(server.CheckListResponse)
+	if err := apiClient.call("CheckList", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// RepairList fixes the repairable problems in the list with the given name, provided the list's
+// StateID is still stateId.
+func (apiClient *Client) RepairList(listName, stateId string) (*server.RepairListResponse, error) {
+	reqObj := &server.RepairListRequest{
+		ListName: listName,
+		StateID:  stateId,
+	}
+	respObj := new(server.RepairListResponse)
+	if err := apiClient.call("RepairList", reqObj, respObj); err != nil {
+		return nil, err
+	}
+	return respObj, nil
+}
+
+// Subscribe returns a channel on which the server sends a ChangeEvent each time the list with the
+// given name changes. If listName is empty, changes to all lists are sent.
+//
+// Subscribe also returns a function that cancels the subscription. After the subscription is
+// canceled, or if the connection to the server fails, the channel is closed.
+func (apiClient *Client) Subscribe(listName string) (<-chan server.ChangeEvent, func(), error) {
+	conn, err := apiClient.dial()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Find out where the feed is now, so that we only send events that occur after Subscribe
+	// returns.
+	seqResp := new(server.GetChangeSeqResponse)
+	if err := conn.Call("Server.GetChangeSeq", &server.GetChangeSeqRequest{}, seqResp); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	ch := make(chan server.ChangeEvent)
+	done := make(chan struct{})
+	go func() {
+		// However the subscription ends, the connection is closed. (Closing it twice, here and in
+		// cancel, is harmless.)
+		defer conn.Close()
+		defer close(ch)
+		afterSeq := seqResp.Seq
+		for {
+			reqObj := &server.WaitForChangesRequest{
+				ListName: listName,
+				AfterSeq: afterSeq,
+			}
+			respObj := new(server.WaitForChangesResponse)
+			if err := conn.Call("Server.WaitForChanges", reqObj, respObj); err != nil {
+				return
+			}
+			for _, ev := range respObj.Events {
+				select {
+				case ch <- ev:
+				case <-done:
+					return
+				}
+				afterSeq = ev.Seq
+			}
+		}
+	}()
 
-	if resp.StatusCode == 200 {
-		return respObj, nil
-	} else {
-		fmt.Printf("DEBUG: server response: '%s'", string(respB))
-		return respObj, fmt.Errorf("error: response code; body in logs")
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			close(done)
+			conn.Close()
+		})
 	}
+	return ch, cancel, nil
 }
 
 // NewClient returns a fresh Client.
@@ -1,11 +1,13 @@
 package client
 
 import (
+	"errors"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -112,3 +114,390 @@ func Test_Client_InsertTask(t *testing.T) {
 	)
 	assert.Nil(err)
 }
+
+func Test_Client_Unblock(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	_, err := client.Unblock(common.GetLineID("make_pasta", "\t[b cooked]"))
+	assert.Nil(err)
+
+	resp, err := client.GetTaskList("make_pasta")
+	assert.Nil(err)
+	assert.Equal("put water in pot", common.Top(resp.Result).Referent)
+}
+
+func Test_Client_SetMetadata(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	md, _ := common.ParseMetadata("#kitchen ~10m")
+	resp, err := client.SetMetadata(common.GetLineID("make_pasta", "\tboil water"), md)
+	assert.Nil(err)
+	assert.Equal(common.GetLineID("make_pasta", "\tboil water #kitchen ~10m"), resp.LineID)
+}
+
+func Test_Client_GetTimeReport(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	_, err := client.ArchiveLine(common.GetLineID("make_pasta", "\t\tput water in pot"))
+	assert.Nil(err)
+
+	resp, err := client.GetTimeReport("task")
+	assert.Nil(err)
+	assert.Equal(1, len(resp.Entries))
+	assert.Equal("make_pasta: make pasta", resp.Entries[0].Key)
+}
+
+func Test_Client_Focus(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	_, err := client.StartFocus("make_pasta", 25*time.Minute)
+	assert.Nil(err)
+
+	resp, err := client.GetFocus()
+	assert.Nil(err)
+	assert.Equal("make_pasta", resp.Session.ListName)
+
+	_, err = client.StopFocus()
+	assert.Nil(err)
+}
+
+func Test_Client_Snooze(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	_, err := client.Snooze(common.GetLineID("make_pasta", "\tboil water"), time.Now().Add(time.Hour))
+	assert.Nil(err)
+
+	resp, err := client.GetTaskList("make_pasta")
+	assert.Nil(err)
+	assert.Equal("put pasta in water", common.Top(resp.Result).Referent)
+}
+
+func Test_Client_Recurrence(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	addResp, err := client.AddRecurrence("make_pasta", "every day 18:00", common.NewTask(common.NewTreeNode("make pasta")))
+	assert.Nil(err)
+	id := addResp.Recurrence.ID
+
+	_, err = client.SetRecurrencePaused(id, true)
+	assert.Nil(err)
+
+	resp, err := client.GetRecurrences("make_pasta")
+	assert.Nil(err)
+	assert.Equal(1, len(resp.Recurrences))
+	assert.True(resp.Recurrences[0].Paused)
+
+	_, err = client.DeleteRecurrence(id)
+	assert.Nil(err)
+}
+
+func Test_Client_Search(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	resp, err := client.Search("drain", server.SearchSubstring, false)
+	assert.Nil(err)
+	assert.Equal(1, len(resp.Results))
+	assert.Equal(common.GetLineID("make_pasta", "\tdrain pasta"), resp.Results[0].LineID)
+}
+
+func Test_Client_Query(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	resp, err := client.Query("make_pasta", "leaf under:'boil water'")
+	assert.Nil(err)
+	assert.Equal(3, len(resp.Results))
+}
+
+func Test_Client_Export(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	resp, err := client.Export("make_pasta", "org")
+	assert.Nil(err)
+	assert.Contains(resp.Data, "** TODO boil water\n")
+}
+
+func Test_Client_Import(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	resp, err := client.Import("make_pasta", "todotxt", "set table\n")
+	assert.Nil(err)
+	assert.Equal(1, resp.Imported)
+
+	listResp, err := client.GetTaskList("make_pasta")
+	assert.Nil(err)
+	assert.Equal("set table", listResp.Result[1].RootNode.Referent)
+}
+
+func Test_Client_CheckList(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	resp, err := client.CheckList("malformed/excess_delta_indent")
+	assert.Nil(err)
+	assert.Equal(1, len(resp.Result.Problems))
+
+	_, err = client.RepairList("malformed/excess_delta_indent", resp.StateID)
+	assert.Nil(err)
+}
+
+func Test_Client_ErrorTypes(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	_, err := client.Export("malformed/excess_delta_indent", "markdown")
+	var indentErr *server.IndentError
+	if assert.True(errors.As(err, &indentErr)) {
+		assert.Equal(1, indentErr.Line)
+		assert.Equal(4, indentErr.Column)
+	}
+
+	_, err = client.Export("nonexistent", "markdown")
+	var noSuchListErr *server.NoSuchListError
+	assert.True(errors.As(err, &noSuchListErr))
+}
+
+func Test_Client_CreateList(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	_, err := client.CreateList("groceries")
+	assert.Nil(err)
+
+	resp, err := client.GetTaskList("groceries")
+	assert.Nil(err)
+	assert.Equal(0, len(resp.Result))
+
+	_, err = client.GetTaskList("nonexistent")
+	var noSuchListErr *server.NoSuchListError
+	assert.True(errors.As(err, &noSuchListErr))
+}
+
+func Test_Client_Note(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	lineId := common.GetLineID("make_pasta", "\tboil water")
+	_, err := client.SetNote(lineId, "salt it\nlike the sea")
+	assert.Nil(err)
+
+	resp, err := client.GetNote(lineId)
+	assert.Nil(err)
+	assert.Equal("salt it\nlike the sea", resp.Note)
+
+	listResp, err := client.GetTaskList("make_pasta")
+	assert.Nil(err)
+	assert.Equal("salt it\nlike the sea", listResp.Result[0].RootNode.Children[0].Note)
+}
+
+func Test_Client_MoveToList(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	_, err := client.MoveToList(common.GetLineID("make_pasta", "\tboil water"), common.LineID("empty:0"))
+	assert.Nil(err)
+
+	resp, err := client.GetTaskList("empty")
+	assert.Nil(err)
+	assert.Equal("boil water", resp.Result[0].RootNode.Referent)
+}
+
+func Test_Client_ActiveList(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	_, err := client.SetActiveList("multiple_nested")
+	assert.Nil(err)
+
+	resp, err := client.GetActiveList()
+	assert.Nil(err)
+	assert.Equal("multiple_nested", resp.ListName)
+
+	stacksResp, err := client.GetStacks()
+	assert.Nil(err)
+	for _, s := range stacksResp.Stacks {
+		if s.ListName == "multiple_nested" {
+			assert.True(s.Active)
+			assert.Equal([]string{"task 0", "subtask 0", "subsubtask 0"}, s.Top)
+		}
+	}
+}
+
+func Test_Client_Hoist(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	_, err := client.SetHoist("cli", common.GetLineID("multiple_nested", "task 1"))
+	assert.Nil(err)
+
+	_, err = client.Push("cli", "multiple_nested", common.NewTask(common.NewTreeNode("subtask 0.5")))
+	assert.Nil(err)
+	resp, err := client.GetHoist("cli")
+	assert.Nil(err)
+	assert.Equal([]string{"task 1"}, resp.Hoist.Path)
+	assert.Equal("subtask 0.5", resp.Task.RootNode.Children[0].Referent)
+
+	popResp, err := client.Pop("cli", "multiple_nested")
+	assert.Nil(err)
+	assert.Equal([]string{"task 1", "subtask 0.5"}, popResp.Path)
+
+	_, err = client.Unhoist("cli")
+	assert.Nil(err)
+	resp, err = client.GetHoist("cli")
+	assert.Nil(err)
+	assert.Nil(resp.Hoist)
+}
+
+func Test_Client_GetStats(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	_, err := client.ArchiveLine(common.GetLineID("make_pasta", "\t\tput water in pot"))
+	assert.Nil(err)
+
+	resp, err := client.GetStats(3)
+	assert.Nil(err)
+	assert.Equal(3, len(resp.Stats.Days))
+	assert.Equal(1, resp.Stats.Completions)
+}
+
+func Test_Client_GetInterruptReport(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	_, err := client.InsertTask(common.LineID("make_pasta:0"), common.NewTask(common.NewTreeNode("check Twitter")))
+	assert.Nil(err)
+
+	resp, err := client.GetInterruptReport()
+	assert.Nil(err)
+	assert.Equal(1, len(resp.Summaries))
+	assert.Equal(1, resp.Summaries[0].Count)
+}
+
+func Test_Client_Review(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	resp, err := client.GetStaleNodes("make_pasta", 0)
+	assert.Nil(err)
+	assert.Equal(1, len(resp.Nodes))
+	assert.Equal([]string{"make pasta"}, resp.Nodes[0].Path)
+
+	_, err = client.Touch(resp.Nodes[0].LineID)
+	assert.Nil(err)
+
+	_, err = client.ArchiveSubtree(resp.Nodes[0].LineID)
+	assert.Nil(err)
+	listResp, err := client.GetTaskList("make_pasta")
+	assert.Nil(err)
+	assert.Equal(0, len(listResp.Result))
+}
+
+func Test_Client_Subscribe(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	_, client, cleanup := testServerAndClient()
+	defer cleanup()
+	events, cancel, err := client.Subscribe("make_pasta")
+	assert.Nil(err)
+	defer cancel()
+
+	resp, err := client.Push("cli", "make_pasta", common.NewTask(common.NewTreeNode("set the table")))
+	if !assert.Nil(err) {
+		return
+	}
+
+	select {
+	case ev := <-events:
+		assert.Equal("make_pasta", ev.ListName)
+		assert.Equal("Push", ev.Op)
+		assert.Equal(resp.LineID, ev.LineID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for change event")
+	}
+}
+
+// Tests that a line pasted from a space-indented list resolves to an ID that the server accepts.
+func Test_Client_ResolveLine_Spaces(t *testing.T) {
+	// no t.Parallel() so we don't have to worry about giving out unique server ports
+	assert := assert.New(t)
+
+	addr := "127.0.0.1:30272"
+	ts, tsCleanup := server.NewBasicTaskstoreWithTestdata()
+	defer tsCleanup()
+	assert.Nil(ts.SetIndent(server.Indent2Spaces))
+	a := common.NewTreeNode("make pasta")
+	b := common.NewTreeNode("boil water")
+	a.AddChild(b)
+	b.AddChild(common.NewTreeNode("put water in pot"))
+	assert.Nil(ts.PutList("spaces", []*common.Task{common.NewTask(a)}))
+
+	apiServer := server.NewServer(ts)
+	if err := apiServer.Start(addr); err != nil {
+		panic("failed to start test server on " + addr + ": " + err.Error())
+	}
+	defer apiServer.Stop()
+	client := NewClient(addr)
+
+	resp, err := client.ResolveLine("spaces", "    put water in pot")
+	assert.Nil(err)
+	assert.Equal(common.GetLineID("spaces", "\t\tput water in pot"), resp.LineID)
+	_, err = client.ArchiveLine(resp.LineID)
+	assert.Nil(err)
+
+	// Once the line's gone, there's nothing to resolve.
+	_, err = client.ResolveLine("spaces", "    put water in pot")
+	assert.NotNil(err)
+	_, err = client.ResolveLine("spaces", "  boil water")
+	assert.Nil(err)
+}
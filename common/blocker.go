@@ -0,0 +1,72 @@
+package common
+
+import (
+	"regexp"
+	"strings"
+)
+
+// BlockerKind identifies what sort of thing a blocked frame is waiting on.
+type BlockerKind string
+
+const (
+	// BlockedOn means the frame can't proceed until some condition is met, as in `[b cooked]`.
+	BlockedOn BlockerKind = "b"
+	// WaitingFor means the frame can't proceed until somebody else does something, as in
+	// `[w plumber]`.
+	WaitingFor BlockerKind = "w"
+)
+
+// blockerPattern matches a blocker annotation within a referent.
+var blockerPattern = regexp.MustCompile(`\[([bw]) ([^\]]+)\]`)
+
+// Blocker is a bracketed annotation on a frame indicating that it can't be worked on yet.
+//
+// A frame may consist of nothing but a blocker (e.g. `[b cooked]`, meaning "wait until the pasta is
+// cooked"), or a blocker may be attached to a frame that also has text (e.g. `fix sink [w
+// plumber]`).
+type Blocker struct {
+	Kind BlockerKind `json:"kind"`
+	// On describes what the frame is waiting for, e.g. "cooked" or "plumber".
+	On string `json:"on"`
+}
+
+// ParseBlockers extracts the blocker annotations from referent.
+//
+// It returns the blockers, in the order in which they appear, along with the referent's text with
+// the annotations removed. Along with each annotation, one space next to it (preferably the one
+// before it) is removed; the rest of the text, whitespace included, is left as it was.
+func ParseBlockers(referent string) ([]Blocker, string) {
+	blockers := make([]Blocker, 0)
+	for _, m := range blockerPattern.FindAllStringSubmatch(referent, -1) {
+		blockers = append(blockers, Blocker{
+			Kind: BlockerKind(m[1]),
+			On:   strings.TrimSpace(m[2]),
+		})
+	}
+
+	var b strings.Builder
+	prev := 0
+	for _, loc := range blockerPattern.FindAllStringIndex(referent, -1) {
+		start, end := loc[0], loc[1]
+		if start > prev && referent[start-1] == ' ' {
+			start--
+		} else if end < len(referent) && referent[end] == ' ' {
+			end++
+		}
+		b.WriteString(referent[prev:start])
+		prev = end
+	}
+	b.WriteString(referent[prev:])
+	return blockers, b.String()
+}
+
+// Blockers returns the blocker annotations in n's referent.
+func (n *TreeNode) Blockers() []Blocker {
+	blockers, _ := ParseBlockers(n.Referent)
+	return blockers
+}
+
+// Blocked determines whether n is waiting on something.
+func (n *TreeNode) Blocked() bool {
+	return blockerPattern.MatchString(n.Referent)
+}
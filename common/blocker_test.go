@@ -0,0 +1,81 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBlockers(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	type testCase struct {
+		Referent    string
+		ExpBlockers []Blocker
+		ExpText     string
+	}
+
+	testCases := []testCase{
+		testCase{
+			Referent:    "drain pasta",
+			ExpBlockers: []Blocker{},
+			ExpText:     "drain pasta",
+		},
+		testCase{
+			Referent:    "[b cooked]",
+			ExpBlockers: []Blocker{Blocker{Kind: BlockedOn, On: "cooked"}},
+			ExpText:     "",
+		},
+		testCase{
+			Referent:    "fix sink [w plumber]",
+			ExpBlockers: []Blocker{Blocker{Kind: WaitingFor, On: "plumber"}},
+			ExpText:     "fix sink",
+		},
+		testCase{
+			Referent: "[b parts arrive] fix sink [w plumber]",
+			ExpBlockers: []Blocker{
+				Blocker{Kind: BlockedOn, On: "parts arrive"},
+				Blocker{Kind: WaitingFor, On: "plumber"},
+			},
+			ExpText: "fix sink",
+		},
+		// whitespace elsewhere in the text is left alone
+		testCase{
+			Referent:    "fix  sink\tnow [w plumber]",
+			ExpBlockers: []Blocker{Blocker{Kind: WaitingFor, On: "plumber"}},
+			ExpText:     "fix  sink\tnow",
+		},
+		testCase{
+			Referent:    "[b parts arrive] fix  sink",
+			ExpBlockers: []Blocker{Blocker{Kind: BlockedOn, On: "parts arrive"}},
+			ExpText:     "fix  sink",
+		},
+		// not blocker annotations
+		testCase{
+			Referent:    "[x cooked]",
+			ExpBlockers: []Blocker{},
+			ExpText:     "[x cooked]",
+		},
+		testCase{
+			Referent:    "[b] [bcooked]",
+			ExpBlockers: []Blocker{},
+			ExpText:     "[b] [bcooked]",
+		},
+	}
+
+	for _, tc := range testCases {
+		blockers, text := ParseBlockers(tc.Referent)
+		assert.Equal(tc.ExpBlockers, blockers, tc.Referent)
+		assert.Equal(tc.ExpText, text, tc.Referent)
+	}
+}
+
+func TestTreeNode_Blocked(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	assert.True(NewTreeNode("[b cooked]").Blocked())
+	assert.True(NewTreeNode("fix sink [w plumber]").Blocked())
+	assert.False(NewTreeNode("drain pasta").Blocked())
+}
@@ -0,0 +1,192 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// dueLayout is the format of the date in a `due:` token.
+const dueLayout = "2006-01-02"
+
+// notBeforeLayout is the format of the time in an `after:` token. It's interpreted in the local
+// time zone.
+const notBeforeLayout = "2006-01-02T15:04"
+
+var (
+	// tagPattern matches a tag token, e.g. `#work`.
+	tagPattern = regexp.MustCompile(`^#([\p{L}\p{N}_/-]+)$`)
+	// duePattern matches a due date token, e.g. `due:2026-11-01`.
+	duePattern = regexp.MustCompile(`^due:(\d{4}-\d{2}-\d{2})$`)
+	// estimatePattern matches a time estimate token, e.g. `~30m` or `~1h30m`.
+	estimatePattern = regexp.MustCompile(`^~((\d+h)?(\d+m)?)$`)
+	// notBeforePattern matches a not-before time token, e.g. `after:2026-10-19T09:00`.
+	notBeforePattern = regexp.MustCompile(`^after:(\d{4}-\d{2}-\d{2}T\d{2}:\d{2})$`)
+)
+
+// Metadata is structured information about a node.
+//
+// Metadata isn't stored separately from the node's referent. Rather, it's parsed from (and
+// serialized back into) inline tokens in the referent:
+//
+//	#work           a tag
+//	due:2026-11-01  a due date
+//	~30m            an estimate of how long the task will take
+//	after:2026-10-19T09:00
+//	                a time before which the node is snoozed (see TreeNode.Snoozed)
+//
+// So, for example, the referent `write report #work due:2026-11-01 ~2h` has the text "write
+// report" and metadata with those three values.
+type Metadata struct {
+	Tags     []string      `json:"tags,omitempty"`
+	Due      *time.Time    `json:"due,omitempty"`
+	Estimate time.Duration `json:"estimate,omitempty"`
+	// NotBefore is the time until which the node is snoozed.
+	NotBefore *time.Time `json:"not_before,omitempty"`
+}
+
+// IsZero determines whether md contains any metadata at all.
+func (md Metadata) IsZero() bool {
+	return len(md.Tags) == 0 && md.Due == nil && md.Estimate == 0 && md.NotBefore == nil
+}
+
+// HasTag determines whether md includes the given tag.
+func (md Metadata) HasTag(tag string) bool {
+	for _, t := range md.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Satisfies determines whether md meets all the criteria in filter.
+//
+// md satisfies filter if md has all the tags in filter; if md is due no later than filter.Due (when
+// filter.Due is set); and if md's estimate is no greater than filter.Estimate (when
+// filter.Estimate is set).
+func (md Metadata) Satisfies(filter Metadata) bool {
+	for _, t := range filter.Tags {
+		if !md.HasTag(t) {
+			return false
+		}
+	}
+	if filter.Due != nil && (md.Due == nil || md.Due.After(*filter.Due)) {
+		return false
+	}
+	if filter.Estimate != 0 && (md.Estimate == 0 || md.Estimate > filter.Estimate) {
+		return false
+	}
+	return true
+}
+
+// Tokens returns the inline tokens representing md, in canonical order: tags, due date, estimate,
+// and then not-before time.
+func (md Metadata) Tokens() []string {
+	tokens := make([]string, 0)
+	for _, t := range md.Tags {
+		tokens = append(tokens, "#"+t)
+	}
+	if md.Due != nil {
+		tokens = append(tokens, "due:"+md.Due.Format(dueLayout))
+	}
+	if md.Estimate != 0 {
+		tokens = append(tokens, "~"+formatEstimate(md.Estimate))
+	}
+	if md.NotBefore != nil {
+		tokens = append(tokens, "after:"+md.NotBefore.In(time.Local).Format(notBeforeLayout))
+	}
+	return tokens
+}
+
+// formatEstimate returns the representation of d used in estimate tokens, e.g. "1h30m".
+//
+// d is truncated to the minute.
+func formatEstimate(d time.Duration) string {
+	h := d / time.Hour
+	m := (d % time.Hour) / time.Minute
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	default:
+		return fmt.Sprintf("%dh%dm", h, m)
+	}
+}
+
+// ParseMetadata extracts the metadata tokens from referent.
+//
+// It returns the metadata, along with the referent's text with the metadata tokens removed. If a
+// token appears more than once (other than tags), the last one wins.
+func ParseMetadata(referent string) (Metadata, string) {
+	md := Metadata{}
+	words := make([]string, 0)
+	for _, w := range strings.Fields(referent) {
+		if m := tagPattern.FindStringSubmatch(w); m != nil {
+			if !md.HasTag(m[1]) {
+				md.Tags = append(md.Tags, m[1])
+			}
+			continue
+		}
+		if m := duePattern.FindStringSubmatch(w); m != nil {
+			if due, err := time.Parse(dueLayout, m[1]); err == nil {
+				md.Due = &due
+				continue
+			}
+		}
+		if m := estimatePattern.FindStringSubmatch(w); m != nil && m[1] != "" {
+			if est, err := time.ParseDuration(m[1]); err == nil {
+				md.Estimate = est
+				continue
+			}
+		}
+		if m := notBeforePattern.FindStringSubmatch(w); m != nil {
+			if nb, err := time.ParseInLocation(notBeforeLayout, m[1], time.Local); err == nil {
+				md.NotBefore = &nb
+				continue
+			}
+		}
+		words = append(words, w)
+	}
+	return md, strings.Join(words, " ")
+}
+
+// FormatMetadata returns a referent consisting of text followed by the tokens representing md.
+//
+// It's the inverse of ParseMetadata.
+func FormatMetadata(text string, md Metadata) string {
+	return strings.Join(append(strings.Fields(text), md.Tokens()...), " ")
+}
+
+// Metadata returns the metadata parsed from n's referent.
+func (n *TreeNode) Metadata() Metadata {
+	md, _ := ParseMetadata(n.Referent)
+	return md
+}
+
+// SetMetadata replaces the metadata in n's referent with md.
+func (n *TreeNode) SetMetadata(md Metadata) {
+	_, text := ParseMetadata(n.Referent)
+	n.Referent = FormatMetadata(text, md)
+}
+
+// Snoozed determines whether n is snoozed as of now: that is, whether n's metadata has a NotBefore
+// time that hasn't arrived yet.
+//
+// Snoozed nodes, along with their descendants, are hidden from the stack until their time comes.
+func (n *TreeNode) Snoozed(now time.Time) bool {
+	md := n.Metadata()
+	return md.NotBefore != nil && now.Before(*md.NotBefore)
+}
+
+// Hidden determines whether n is hidden as of now, because it or one of its ancestors is snoozed.
+func (n *TreeNode) Hidden(now time.Time) bool {
+	for ; n != nil; n = n.Parent {
+		if n.Snoozed(now) {
+			return true
+		}
+	}
+	return false
+}
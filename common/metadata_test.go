@@ -0,0 +1,166 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// date returns a pointer to the time.Time for the given date string, which must be in dueLayout.
+func date(s string) *time.Time {
+	d, err := time.Parse(dueLayout, s)
+	if err != nil {
+		panic(err.Error())
+	}
+	return &d
+}
+
+func TestParseMetadata(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	type testCase struct {
+		Referent string
+		ExpMd    Metadata
+		ExpText  string
+	}
+
+	testCases := []testCase{
+		testCase{
+			Referent: "drain pasta",
+			ExpMd:    Metadata{},
+			ExpText:  "drain pasta",
+		},
+		testCase{
+			Referent: "write report #work due:2026-11-01 ~2h",
+			ExpMd: Metadata{
+				Tags:     []string{"work"},
+				Due:      date("2026-11-01"),
+				Estimate: 2 * time.Hour,
+			},
+			ExpText: "write report",
+		},
+		testCase{
+			Referent: "#home ~1h30m fix sink #urgent [w plumber]",
+			ExpMd: Metadata{
+				Tags:     []string{"home", "urgent"},
+				Estimate: 90 * time.Minute,
+			},
+			ExpText: "fix sink [w plumber]",
+		},
+		// not metadata tokens
+		testCase{
+			Referent: "# due:tomorrow ~ ~soon",
+			ExpMd:    Metadata{},
+			ExpText:  "# due:tomorrow ~ ~soon",
+		},
+	}
+
+	for _, tc := range testCases {
+		md, text := ParseMetadata(tc.Referent)
+		assert.Equal(tc.ExpMd, md, tc.Referent)
+		assert.Equal(tc.ExpText, text, tc.Referent)
+	}
+}
+
+func TestFormatMetadata(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	md := Metadata{
+		Tags:     []string{"work", "q4"},
+		Due:      date("2026-11-01"),
+		Estimate: 45 * time.Minute,
+	}
+	referent := FormatMetadata("write report", md)
+	assert.Equal("write report #work #q4 due:2026-11-01 ~45m", referent)
+
+	// round trip
+	rsltMd, rsltText := ParseMetadata(referent)
+	assert.Equal(md, rsltMd)
+	assert.Equal("write report", rsltText)
+
+	assert.Equal("write report", FormatMetadata("write report", Metadata{}))
+}
+
+func TestTreeNode_SetMetadata(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	n := NewTreeNode("#home fix sink ~1h")
+	md := n.Metadata()
+	md.Tags = append(md.Tags, "urgent")
+	md.Estimate = 0
+	n.SetMetadata(md)
+	assert.Equal("fix sink #home #urgent", n.Referent)
+}
+
+func TestMetadata_Satisfies(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	md := Metadata{
+		Tags:     []string{"work", "q4"},
+		Due:      date("2026-11-01"),
+		Estimate: 45 * time.Minute,
+	}
+
+	assert.True(md.Satisfies(Metadata{}))
+	assert.True(md.Satisfies(Metadata{Tags: []string{"q4"}}))
+	assert.False(md.Satisfies(Metadata{Tags: []string{"q4", "home"}}))
+	assert.True(md.Satisfies(Metadata{Due: date("2026-11-01")}))
+	assert.False(md.Satisfies(Metadata{Due: date("2026-10-31")}))
+	assert.True(md.Satisfies(Metadata{Estimate: time.Hour}))
+	assert.False(md.Satisfies(Metadata{Estimate: 30 * time.Minute}))
+
+	// a node without a due date or estimate doesn't satisfy a filter on those
+	assert.False(Metadata{}.Satisfies(Metadata{Due: date("2026-11-01")}))
+	assert.False(Metadata{}.Satisfies(Metadata{Estimate: time.Hour}))
+}
+
+func TestTreeNode_MarshalJSON_Metadata(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	b, err := json.Marshal(NewTreeNode("write report #work due:2026-11-01"))
+	assert.Nil(err)
+	assert.JSONEq(
+		`{
+			"referent": "write report #work due:2026-11-01",
+			"metadata": {"tags": ["work"], "due": "2026-11-01T00:00:00Z"}
+		}`,
+		string(b),
+	)
+}
+
+func TestParseMetadata_NotBefore(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	md, text := ParseMetadata("call plumber after:2026-10-19T09:00")
+	assert.Equal("call plumber", text)
+	exp := time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local)
+	assert.True(exp.Equal(*md.NotBefore))
+
+	// round trip
+	assert.Equal("call plumber after:2026-10-19T09:00", FormatMetadata(text, md))
+}
+
+func TestTreeNode_Snoozed(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.Local)
+	n := NewTreeNode("call plumber after:2026-10-19T09:00")
+	child := NewTreeNode("find plumber's number")
+	n.AddChild(child)
+
+	assert.True(n.Snoozed(now))
+	assert.False(child.Snoozed(now))
+	assert.True(child.Hidden(now))
+	assert.False(n.Snoozed(now.Add(time.Hour)))
+	assert.False(child.Hidden(now.Add(time.Hour)))
+	assert.False(NewTreeNode("call plumber").Snoozed(now))
+}
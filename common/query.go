@@ -0,0 +1,381 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// queryFunc determines whether a node matches (part of) a query, as of now.
+type queryFunc func(n *TreeNode, now time.Time) bool
+
+// Query selects nodes by their structure, state, and metadata.
+//
+// A query is made up of predicates, combined with `and`, `or`, `not`, and parentheses. Predicates
+// that appear side by side with no operator between them are combined with `and`. The predicates
+// are:
+//
+//	leaf            nodes with no children
+//	root            nodes with no parent (i.e. tasks proper)
+//	top             the top frame of each task (see Top)
+//	blocked         nodes with blockers (see Blocker)
+//	snoozed         nodes that are snoozed (see TreeNode.Snoozed)
+//	'some text'     nodes whose referents contain the given text
+//	text:water      same as above
+//	under:'boil water'
+//	                nodes with an ancestor whose text (sans metadata) is exactly the given text
+//	tag:work        nodes with the given tag
+//	depth>3         nodes with more than 3 ancestors (also <, <=, =, >=)
+//	due<tomorrow    nodes due before the given date (also <=, =, >, >=). The date may be written
+//	                as YYYY-MM-DD or as `yesterday`, `today`, or `tomorrow`.
+//	estimate<=30m   nodes with an estimate of at most the given duration (also <, =, >, >=)
+//
+// Values containing spaces or parentheses can be quoted with single or double quotes. Nodes
+// without a due date or an estimate never match predicates on them.
+//
+// For example, `leaf under:'make pasta'` selects the leaves of the "make pasta" task, and
+// `tag:work and (due<tomorrow or blocked)` selects work items that are due today or blocked.
+type Query struct {
+	src   string
+	match queryFunc
+}
+
+// String returns the source from which q was parsed.
+func (q *Query) String() string {
+	return q.src
+}
+
+// Match determines whether n matches q as of now.
+func (q *Query) Match(n *TreeNode, now time.Time) bool {
+	return q.match(n, now)
+}
+
+// Select returns the nodes in taskList that match q as of now, in the order in which Walk visits
+// them.
+func (q *Query) Select(taskList []*Task, now time.Time) []*TreeNode {
+	rslt := make([]*TreeNode, 0)
+	for _, t := range taskList {
+		t.RootNode.Walk(func(n *TreeNode) error {
+			if q.match(n, now) {
+				rslt = append(rslt, n)
+			}
+			return nil
+		})
+	}
+	return rslt
+}
+
+// queryToken is a lexical token of a query: a parenthesis, an operator, or a predicate.
+type queryToken struct {
+	s string
+	// quoteAt is the index in s at which the first quoted section began, or -1 if nothing in the
+	// token was quoted. The quotes themselves are not included in s.
+	quoteAt int
+}
+
+// lexQuery splits src into tokens.
+func lexQuery(src string) ([]queryToken, error) {
+	tokens := make([]queryToken, 0)
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if unicode.IsSpace(r) {
+			i++
+			continue
+		}
+		if r == '(' || r == ')' {
+			tokens = append(tokens, queryToken{s: string(r), quoteAt: -1})
+			i++
+			continue
+		}
+
+		tok := queryToken{quoteAt: -1}
+		var b strings.Builder
+		for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+			if q := runes[i]; q == '\'' || q == '"' {
+				end := i + 1
+				for end < len(runes) && runes[end] != q {
+					end++
+				}
+				if end == len(runes) {
+					return nil, fmt.Errorf("unterminated quote in query `%s`", src)
+				}
+				if tok.quoteAt < 0 {
+					tok.quoteAt = b.Len()
+				}
+				b.WriteString(string(runes[i+1 : end]))
+				i = end + 1
+				continue
+			}
+			b.WriteRune(runes[i])
+			i++
+		}
+		tok.s = b.String()
+		tokens = append(tokens, tok)
+	}
+	return tokens, nil
+}
+
+// queryParser is a recursive-descent parser for the query language.
+//
+// The grammar is:
+//
+//	or   = and { "or" and }
+//	and  = not { ["and"] not }
+//	not  = "not" not | "(" or ")" | predicate
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+// peek returns the next token, or nil if there are none left.
+func (p *queryParser) peek() *queryToken {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+// isKeyword determines whether tok is the given (case-insensitive) keyword, as opposed to, say,
+// quoted text that happens to match it.
+func isKeyword(tok *queryToken, kw string) bool {
+	return tok != nil && tok.quoteAt < 0 && strings.EqualFold(tok.s, kw)
+}
+
+func (p *queryParser) parseOr() (queryFunc, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for isKeyword(p.peek(), "or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(n *TreeNode, now time.Time) bool { return l(n, now) || right(n, now) }
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryFunc, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok == nil || tok.s == ")" && tok.quoteAt < 0 || isKeyword(tok, "or") {
+			return left, nil
+		}
+		if isKeyword(tok, "and") {
+			p.pos++
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(n *TreeNode, now time.Time) bool { return l(n, now) && right(n, now) }
+	}
+}
+
+func (p *queryParser) parseNot() (queryFunc, error) {
+	tok := p.peek()
+	if tok == nil {
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+	p.pos++
+
+	if isKeyword(tok, "not") {
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(n *TreeNode, now time.Time) bool { return !inner(n, now) }, nil
+	}
+	if tok.quoteAt < 0 && tok.s == "(" {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing == nil || closing.quoteAt >= 0 || closing.s != ")" {
+			return nil, fmt.Errorf("missing `)`")
+		}
+		p.pos++
+		return inner, nil
+	}
+	if tok.quoteAt < 0 && (tok.s == ")" || isKeyword(tok, "and") || isKeyword(tok, "or")) {
+		return nil, fmt.Errorf("unexpected `%s`", tok.s)
+	}
+	return parsePredicate(tok)
+}
+
+// queryDate resolves a date in a due predicate, relative to now, into dueLayout.
+func queryDate(s string, now time.Time) string {
+	now = now.In(time.Local)
+	switch strings.ToLower(s) {
+	case "yesterday":
+		return now.AddDate(0, 0, -1).Format(dueLayout)
+	case "today":
+		return now.Format(dueLayout)
+	case "tomorrow":
+		return now.AddDate(0, 0, 1).Format(dueLayout)
+	}
+	return s
+}
+
+// compare determines whether the comparison `a op b` holds, given c, the result of comparing a and
+// b (negative if a < b, and so on).
+func compare(c int, op string) bool {
+	switch op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return c == 0
+}
+
+// sign returns -1, 0, or 1 according to whether a is less than, equal to, or greater than b.
+func sign(a, b int64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// parsePredicate parses a single predicate.
+func parsePredicate(tok *queryToken) (queryFunc, error) {
+	if tok.quoteAt == 0 {
+		text := tok.s
+		return func(n *TreeNode, now time.Time) bool { return strings.Contains(n.Referent, text) }, nil
+	}
+
+	limit := len(tok.s)
+	if tok.quoteAt >= 0 {
+		limit = tok.quoteAt
+	}
+	i := strings.IndexAny(tok.s[:limit], ":<>=")
+	if i < 0 {
+		switch strings.ToLower(tok.s) {
+		case "leaf":
+			return func(n *TreeNode, now time.Time) bool { return len(n.Children) == 0 }, nil
+		case "root":
+			return func(n *TreeNode, now time.Time) bool { return n.Parent == nil }, nil
+		case "top":
+			return func(n *TreeNode, now time.Time) bool {
+				root := n
+				for root.Parent != nil {
+					root = root.Parent
+				}
+				return topFrame(root, now) == n
+			}, nil
+		case "blocked":
+			return func(n *TreeNode, now time.Time) bool { return n.Blocked() }, nil
+		case "snoozed":
+			return func(n *TreeNode, now time.Time) bool { return n.Snoozed(now) }, nil
+		}
+		return nil, fmt.Errorf("unknown predicate `%s`", tok.s)
+	}
+
+	key := strings.ToLower(tok.s[:i])
+	op := tok.s[i : i+1]
+	if i+1 < limit && tok.s[i+1] == '=' && (op == "<" || op == ">") {
+		op += "="
+	}
+	value := tok.s[i+len(op):]
+	if op == ":" {
+		op = "="
+	}
+	if value == "" {
+		return nil, fmt.Errorf("missing value in `%s`", tok.s)
+	}
+
+	switch key {
+	case "text", "under", "tag":
+		if op != "=" {
+			return nil, fmt.Errorf("`%s` only supports `:` or `=`", key)
+		}
+	}
+
+	switch key {
+	case "text":
+		return func(n *TreeNode, now time.Time) bool { return strings.Contains(n.Referent, value) }, nil
+	case "under":
+		return func(n *TreeNode, now time.Time) bool {
+			for a := n.Parent; a != nil; a = a.Parent {
+				if _, text := ParseMetadata(a.Referent); text == value {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case "tag":
+		tag := strings.TrimPrefix(value, "#")
+		return func(n *TreeNode, now time.Time) bool { return n.Metadata().HasTag(tag) }, nil
+	case "depth":
+		d, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid depth `%s`", value)
+		}
+		return func(n *TreeNode, now time.Time) bool {
+			return compare(sign(int64(n.Depth()), int64(d)), op)
+		}, nil
+	case "due":
+		if _, err := time.Parse(dueLayout, queryDate(value, time.Now())); err != nil {
+			return nil, fmt.Errorf("invalid date `%s`", value)
+		}
+		return func(n *TreeNode, now time.Time) bool {
+			due := n.Metadata().Due
+			if due == nil {
+				return false
+			}
+			// Dates in dueLayout sort lexically.
+			return compare(strings.Compare(due.Format(dueLayout), queryDate(value, now)), op)
+		}, nil
+	case "estimate":
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration `%s`", value)
+		}
+		return func(n *TreeNode, now time.Time) bool {
+			est := n.Metadata().Estimate
+			if est == 0 {
+				return false
+			}
+			return compare(sign(int64(est), int64(d)), op)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown predicate `%s`", key)
+}
+
+// ParseQuery parses src, which must be in the query language described in the Query docs.
+func ParseQuery(src string) (*Query, error) {
+	tokens, err := lexQuery(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+
+	p := &queryParser{tokens: tokens}
+	match, err := p.parseOr()
+	if err == nil && p.pos < len(tokens) {
+		err = fmt.Errorf("unexpected `%s`", tokens[p.pos].s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid query `%s`: %s", src, err.Error())
+	}
+	return &Query{src: src, match: match}, nil
+}
@@ -0,0 +1,110 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_Select(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.Local)
+	chores := NewTreeNode("chores #home")
+	chores.AddChild(NewTreeNode("fix sink due:2026-10-19 ~1h [w plumber]"))
+	chores.AddChild(NewTreeNode("write report #work due:2026-10-21 ~2h"))
+	chores.AddChild(NewTreeNode("file expenses #work after:2026-10-20T09:00 ~15m"))
+	taskList := append(MakePasta(), NewTask(chores))
+
+	type testCase struct {
+		Query string
+		Exp   []string
+	}
+
+	testCases := []testCase{
+		testCase{
+			Query: "leaf under:'boil water'",
+			Exp:   []string{"put water in pot", "put pot on burner", "turn burner on"},
+		},
+		testCase{
+			Query: "depth>=1 AND not leaf",
+			Exp:   []string{"boil water"},
+		},
+		testCase{
+			Query: "blocked",
+			Exp:   []string{"[b cooked]", "fix sink due:2026-10-19 ~1h [w plumber]"},
+		},
+		testCase{
+			Query: "top",
+			Exp:   []string{"put water in pot", "fix sink due:2026-10-19 ~1h [w plumber]"},
+		},
+		testCase{
+			Query: "tag:work and due<tomorrow",
+			Exp:   []string{},
+		},
+		testCase{
+			Query: "tag:work or due<tomorrow",
+			Exp: []string{
+				"fix sink due:2026-10-19 ~1h [w plumber]",
+				"write report #work due:2026-10-21 ~2h",
+				"file expenses #work after:2026-10-20T09:00 ~15m",
+			},
+		},
+		testCase{
+			Query: "due>=today due<=2026-10-21 estimate>1h",
+			Exp:   []string{"write report #work due:2026-10-21 ~2h"},
+		},
+		testCase{
+			Query: "(tag:#work or tag:home) not snoozed",
+			Exp:   []string{"chores #home", "write report #work due:2026-10-21 ~2h"},
+		},
+		testCase{
+			Query: `root and "pasta"`,
+			Exp:   []string{"make pasta"},
+		},
+		testCase{
+			Query: "text:burner or 'and'",
+			Exp:   []string{"put pot on burner", "turn burner on"},
+		},
+	}
+
+	for _, tc := range testCases {
+		q, err := ParseQuery(tc.Query)
+		if !assert.Nil(err, tc.Query) {
+			continue
+		}
+		assert.Equal(tc.Query, q.String())
+		got := make([]string, 0)
+		for _, n := range q.Select(taskList, now) {
+			got = append(got, n.Referent)
+		}
+		assert.Equal(tc.Exp, got, tc.Query)
+	}
+}
+
+func TestParseQuery_Invalid(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	for _, src := range []string{
+		"",
+		"leaf and",
+		"or leaf",
+		"(leaf",
+		"leaf)",
+		"not",
+		"'unterminated",
+		"shiny",
+		"color:red",
+		"depth>deep",
+		"due<someday",
+		"estimate<=awhile",
+		"tag<work",
+		"text:",
+	} {
+		_, err := ParseQuery(src)
+		assert.NotNil(err, src)
+	}
+}
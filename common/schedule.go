@@ -0,0 +1,229 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduleHorizon is how far into the future Schedule.Next looks for an occurrence before giving
+// up. A schedule like `0 0 31 2 *` never comes due, and we don't want to search forever.
+const scheduleHorizon = 5 * 366 * 24 * time.Hour
+
+// dayNames maps the names (and three-letter abbreviations) of the days of the week to their cron
+// numbers.
+var dayNames = map[string]int{
+	"sun": 0, "sunday": 0,
+	"mon": 1, "monday": 1,
+	"tue": 2, "tuesday": 2,
+	"wed": 3, "wednesday": 3,
+	"thu": 4, "thursday": 4,
+	"fri": 5, "friday": 5,
+	"sat": 6, "saturday": 6,
+}
+
+// Schedule is a set of times at which something recurs, to the precision of a minute.
+//
+// A Schedule can be written either as a standard five-field cron expression:
+//
+//	30 9 * * 1-5    at 9:30 on weekdays
+//	0 */2 1 * *     every two hours on the first of the month
+//
+// or in a friendlier form beginning with `every`, which allows a day (`day`, `weekday`,
+// `weekend`, or a comma-separated list of day names) and optionally a time of day:
+//
+//	every day 7:00
+//	every weekday 9:00
+//	every mon,thu 18:30
+//	every sunday
+//
+// When no time of day is given, the schedule comes due at midnight. Times are interpreted in the
+// time zone of the time passed to Next.
+type Schedule struct {
+	spec string
+
+	minute [60]bool
+	hour   [24]bool
+	dom    [32]bool
+	month  [13]bool
+	dow    [7]bool
+	// domAny and dowAny are set when the day-of-month or day-of-week field is `*`. Per cron
+	// convention, when both fields are restricted, a day matches if it satisfies either of them.
+	domAny bool
+	dowAny bool
+}
+
+// String returns the spec from which s was parsed.
+func (s *Schedule) String() string {
+	return s.spec
+}
+
+// dayMatches determines whether the date of t satisfies s's day-of-month and day-of-week fields.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domOk := s.dom[t.Day()]
+	dowOk := s.dow[int(t.Weekday())]
+	if s.domAny || s.dowAny {
+		return domOk && dowOk
+	}
+	return domOk || dowOk
+}
+
+// Next returns the first time after t at which s comes due, in t's time zone.
+//
+// If s doesn't come due within the next several years, Next returns the zero time.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(scheduleHorizon)
+	for t.Before(limit) {
+		y, mo, d := t.Date()
+		if !s.month[int(mo)] {
+			t = time.Date(y, mo+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(y, mo, d+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.hour[t.Hour()] {
+			t = time.Date(y, mo, d, t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if !s.minute[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// parseCronField parses a single field of a cron expression into set, whose valid indices run from
+// min to max inclusive.
+//
+// A field is a comma-separated list of `*`, single numbers, or ranges like `1-5`, each optionally
+// followed by a step like `/15`. As in cron, a single number followed by a step is where the step
+// starts: `5/15` means every 15 from 5 up to max.
+func parseCronField(field string, set []bool, min, max int) error {
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		stepped := false
+		if i := strings.Index(part, "/"); i >= 0 {
+			stepped = true
+			var err error
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step < 1 {
+				return fmt.Errorf("invalid step in `%s`", part)
+			}
+			part = part[:i]
+		}
+
+		lo, hi := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			lo, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return fmt.Errorf("invalid value `%s`", bounds[0])
+			}
+			hi = lo
+			if stepped {
+				hi = max
+			}
+			if len(bounds) == 2 {
+				hi, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return fmt.Errorf("invalid value `%s`", bounds[1])
+				}
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return fmt.Errorf("`%s` out of range %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return nil
+}
+
+// cronSpec translates the friendly form of a schedule (see Schedule) into a cron expression.
+func cronSpec(spec string) (string, error) {
+	words := strings.Fields(strings.ToLower(spec))
+	if len(words) < 2 || len(words) > 3 {
+		return "", fmt.Errorf("expected `every <days> [<hh:mm>]`")
+	}
+
+	var dow string
+	switch words[1] {
+	case "day":
+		dow = "*"
+	case "weekday":
+		dow = "1-5"
+	case "weekend":
+		dow = "0,6"
+	default:
+		nums := make([]string, 0)
+		for _, name := range strings.Split(words[1], ",") {
+			n, ok := dayNames[name]
+			if !ok {
+				return "", fmt.Errorf("unknown day `%s`", name)
+			}
+			nums = append(nums, strconv.Itoa(n))
+		}
+		dow = strings.Join(nums, ",")
+	}
+
+	hour, minute := 0, 0
+	if len(words) == 3 {
+		t, err := time.Parse("15:04", words[2])
+		if err != nil {
+			return "", fmt.Errorf("invalid time of day `%s`", words[2])
+		}
+		hour, minute = t.Hour(), t.Minute()
+	}
+	return fmt.Sprintf("%d %d * * %s", minute, hour, dow), nil
+}
+
+// ParseSchedule parses spec, which may be in either of the forms described in the Schedule docs.
+func ParseSchedule(spec string) (*Schedule, error) {
+	s := &Schedule{spec: spec}
+
+	cron := spec
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(spec)), "every") {
+		var err error
+		cron, err = cronSpec(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule `%s`: %s", spec, err.Error())
+		}
+	}
+
+	fields := strings.Fields(cron)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule `%s`: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	// Sunday may be written as 7 in the day-of-week field.
+	var dow [8]bool
+	for i, f := range []struct {
+		set      []bool
+		min, max int
+	}{
+		{s.minute[:], 0, 59},
+		{s.hour[:], 0, 23},
+		{s.dom[:], 1, 31},
+		{s.month[:], 1, 12},
+		{dow[:], 0, 7},
+	} {
+		if err := parseCronField(fields[i], f.set, f.min, f.max); err != nil {
+			return nil, fmt.Errorf("invalid schedule `%s`: %s", spec, err.Error())
+		}
+	}
+	copy(s.dow[:], dow[:7])
+	s.dow[0] = s.dow[0] || dow[7]
+	s.domAny = fields[2] == "*"
+	s.dowAny = fields[4] == "*"
+	return s, nil
+}
@@ -0,0 +1,85 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// minute returns the time.Time for the given time string, which must be in notBeforeLayout, in UTC.
+func minute(s string) time.Time {
+	t, err := time.Parse(notBeforeLayout, s)
+	if err != nil {
+		panic(err.Error())
+	}
+	return t
+}
+
+func TestSchedule_Next(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	type testCase struct {
+		Spec  string
+		After string
+		Exp   string
+	}
+
+	// 2026-10-19 is a Monday.
+	testCases := []testCase{
+		testCase{"every day 7:00", "2026-10-19T06:59", "2026-10-19T07:00"},
+		testCase{"every day 7:00", "2026-10-19T07:00", "2026-10-20T07:00"},
+		testCase{"every weekday 9:00", "2026-10-23T09:00", "2026-10-26T09:00"},
+		testCase{"every weekend", "2026-10-19T12:00", "2026-10-24T00:00"},
+		testCase{"every Mon,thu 18:30", "2026-10-19T18:30", "2026-10-22T18:30"},
+		testCase{"30 9 * * 1-5", "2026-10-24T00:00", "2026-10-26T09:30"},
+		testCase{"*/15 * * * *", "2026-10-19T10:07", "2026-10-19T10:15"},
+		// a single number with a step is where the step starts
+		testCase{"5/15 * * * *", "2026-10-19T10:07", "2026-10-19T10:20"},
+		testCase{"5/15 * * * *", "2026-10-19T10:50", "2026-10-19T11:05"},
+		testCase{"0 0 1 * *", "2026-12-15T00:00", "2027-01-01T00:00"},
+		testCase{"0 12 * * 7", "2026-10-19T00:00", "2026-10-25T12:00"},
+		// either the day of the month or the day of the week will do
+		testCase{"0 0 1 * 5", "2026-10-19T00:00", "2026-10-23T00:00"},
+	}
+
+	for _, tc := range testCases {
+		s, err := ParseSchedule(tc.Spec)
+		if !assert.Nil(err, tc.Spec) {
+			continue
+		}
+		assert.Equal(minute(tc.Exp), s.Next(minute(tc.After)), tc.Spec)
+		assert.Equal(tc.Spec, s.String())
+	}
+}
+
+func TestSchedule_Next_Never(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, err := ParseSchedule("0 0 31 2 *")
+	assert.Nil(err)
+	assert.True(s.Next(minute("2026-10-19T00:00")).IsZero())
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	for _, spec := range []string{
+		"",
+		"every",
+		"every fortnight",
+		"every day 25:00",
+		"every day at 9:00",
+		"* * * *",
+		"60 * * * *",
+		"* * * * 1-8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+	} {
+		_, err := ParseSchedule(spec)
+		assert.NotNil(err, spec)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // LineID represents a line in the data file.
@@ -15,7 +16,8 @@ import (
 // A Line ID is composed of two parts, separated by a colon. The first part is the name of a task
 // list, e.g. `make_pasta`. The second part is either 0 (which indicates the top line of the file),
 // or a SHA256 sum of the line's content, indentation included and trailing whitespace excluded (see
-// GetLineID).
+// GetLineID). Indentation is always counted as one tab per level, whatever indent unit the file
+// uses, so a line's ID doesn't change when the file is reindented.
 type LineID string
 
 // GetLineID returns the line ID for the line in the list identified by listName, with content s.
@@ -41,3 +43,37 @@ func (a *Task) Equal(b *Task) bool {
 func NewTask(n *TreeNode) *Task {
 	return &Task{RootNode: n}
 }
+
+// Top returns the frame that should be worked on next in taskList.
+//
+// Ordinarily that's the top frame of the first task: the node that TreeNode.WalkFromTop would visit
+// first. Snoozed nodes (see TreeNode.Snoozed) and their descendants aren't on the stack yet, so
+// they're passed over. And if the top frame is blocked (see Blocker), nothing else in its task can
+// be done until it's unblocked, so Top moves on to the next task, and so forth. This way, Top shows
+// what can actually be done while waiting.
+//
+// If every task is blocked or snoozed, or taskList is empty, Top returns nil.
+func Top(taskList []*Task) *TreeNode {
+	now := time.Now()
+	for _, t := range taskList {
+		n := topFrame(t.RootNode, now)
+		if n != nil && !n.Blocked() {
+			return n
+		}
+	}
+	return nil
+}
+
+// topFrame returns the top frame of the tree rooted at n, disregarding nodes that are snoozed as of
+// now. If n itself is snoozed, topFrame returns nil.
+func topFrame(n *TreeNode, now time.Time) *TreeNode {
+	if n.Snoozed(now) {
+		return nil
+	}
+	for _, cn := range n.Children {
+		if f := topFrame(cn, now); f != nil {
+			return f
+		}
+	}
+	return n
+}
@@ -30,3 +30,35 @@ func TestGetLineID(t *testing.T) {
 		GetLineID("foo", "not_bar"),
 	)
 }
+
+func TestTop(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	// top frame of the first task
+	taskList := MakePasta()
+	assert.Equal("put water in pot", Top(taskList).Referent)
+
+	// first task is blocked, so skip to the next one
+	taskList = MakePasta()
+	taskList[0].RootNode.Children = taskList[0].RootNode.Children[2:]
+	taskList = append(taskList, MultipleNested()...)
+	assert.Equal("[b cooked]", taskList[0].RootNode.Children[0].Referent)
+	assert.Equal("subsubtask 0", Top(taskList).Referent)
+
+	// everything is blocked
+	taskList = []*Task{NewTask(NewTreeNode("fix sink [w plumber]"))}
+	assert.Nil(Top(taskList))
+
+	// snoozed frames are passed over
+	taskList = MakePasta()
+	taskList[0].RootNode.Children[0].Children[0].Referent = "put water in pot after:2999-01-01T00:00"
+	assert.Equal("put pot on burner", Top(taskList).Referent)
+	taskList[0].RootNode.Children[0].Referent = "boil water after:2999-01-01T00:00"
+	assert.Equal("put pasta in water", Top(taskList).Referent)
+	taskList[0].RootNode.Referent = "make pasta after:2999-01-01T00:00"
+	assert.Nil(Top(taskList))
+
+	// nothing at all
+	assert.Nil(Top([]*Task{}))
+}
@@ -23,30 +23,61 @@ type TreeNode struct {
 	Parent   *TreeNode   `json:"-"`
 	Children []*TreeNode `json:"children,omitempty"`
 	Referent string      `json:"referent"`
+	// Note is free-form text attached to n, which may span multiple lines. It's empty if n has no
+	// note.
+	Note string `json:"note,omitempty"`
+	// Comments are the comment lines and blank lines that directly precede n's line in its list's
+	// data, indentation included. They're kept so that they survive being read and written back.
+	Comments []string `json:"comments,omitempty"`
+	// TrailingComments are like Comments, but directly follow n's line. Since each comment is
+	// attached to the line below it, only the bottom node in a list has TrailingComments.
+	TrailingComments []string `json:"trailing_comments,omitempty"`
 
 	mu sync.Mutex `json:"-"`
 }
 
-// TreeNode needs its own UnmarshalJSON so that .Children gets initialized.
+// TreeNode needs its own UnmarshalJSON so that .Children gets initialized, and so that each child's
+// .Parent points back to n.
 //
-// Initializing .Parent is done further up the call stack.
+// n's own .Parent is initialized further up the call stack, when n's parent is unmarshaled.
 func (n *TreeNode) UnmarshalJSON(b []byte) error {
-	type tmpTreeNode *TreeNode
-	tmp := tmpTreeNode(n)
+	// tmpTreeNode has TreeNode's fields but not its methods, so unmarshaling into it doesn't recurse
+	// back into this function.
+	type tmpTreeNode TreeNode
+	tmp := (*tmpTreeNode)(n)
 	err := json.Unmarshal(b, tmp)
 	if err != nil {
 		return err
 	}
 
-	n.Referent = tmp.Referent
 	if n.Children == nil {
 		n.Children = make([]*TreeNode, 0)
-	} else {
-		copy(n.Children, tmp.Children)
+	}
+	for _, childNode := range n.Children {
+		childNode.Parent = n
 	}
 	return nil
 }
 
+// MarshalJSON includes n's blockers and metadata in its JSON representation, so that clients
+// needn't parse referents themselves.
+func (n *TreeNode) MarshalJSON() ([]byte, error) {
+	type tmpTreeNode TreeNode
+	var md *Metadata
+	if m := n.Metadata(); !m.IsZero() {
+		md = &m
+	}
+	return json.Marshal(struct {
+		*tmpTreeNode
+		Blockers []Blocker `json:"blockers,omitempty"`
+		Metadata *Metadata `json:"metadata,omitempty"`
+	}{
+		tmpTreeNode: (*tmpTreeNode)(n),
+		Blockers:    n.Blockers(),
+		Metadata:    md,
+	})
+}
+
 // Depth returns the number of ancestors of n.
 func (n *TreeNode) Depth() int {
 	i := 0
@@ -80,6 +111,22 @@ func (n *TreeNode) InsertChild(ind int, childNode *TreeNode) {
 	n.Children[ind] = childNode
 }
 
+// RemoveChild detaches childNode, along with its descendants, from n. It returns false if
+// childNode isn't a child of n.
+func (n *TreeNode) RemoveChild(childNode *TreeNode) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for i, ch := range n.Children {
+		if ch == childNode {
+			n.Children = append(n.Children[:i], n.Children[i+1:]...)
+			childNode.Parent = nil
+			return true
+		}
+	}
+	return false
+}
+
 // Walk walks the tree rooted at n, calling fn for each TreeNode, including n.
 //
 // All errors that arise are filtered by fn: see the TreeWalkFunc documentation for details.
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -41,6 +42,24 @@ func TestTreeNode_InsertChild(t *testing.T) {
 	assert.Equal([]string{"a", "b", "c", "d", "e"}, rslt)
 }
 
+func TestTreeNode_RemoveChild(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	n := NewTreeNode("")
+	a := NewTreeNode("a")
+	a.AddChild(NewTreeNode("aa"))
+	n.AddChild(a)
+	n.AddChild(NewTreeNode("b"))
+
+	assert.True(n.RemoveChild(a))
+	assert.Nil(a.Parent)
+	assert.Equal(0, a.Children[0].Depth()-1)
+	assert.Equal(1, len(n.Children))
+	assert.Equal("b", n.Children[0].Referent)
+	assert.False(n.RemoveChild(a))
+}
+
 func TestTreeNode_InsertChild_Empty(t *testing.T) {
 	t.Parallel()
 	assert := assert.New(t)
@@ -297,3 +316,36 @@ func TestTreeNode_Depth(t *testing.T) {
 	assert.Equal(1, b.Depth())
 	assert.Equal(2, c.Depth())
 }
+
+func TestTreeNode_MarshalJSON(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	n := NewTreeNode("fix sink [w plumber]")
+	n.AddChild(NewTreeNode("[b parts arrive]"))
+	b, err := json.Marshal(n)
+	assert.Nil(err)
+	assert.JSONEq(
+		`{
+			"referent": "fix sink [w plumber]",
+			"blockers": [{"kind": "w", "on": "plumber"}],
+			"children": [
+				{
+					"referent": "[b parts arrive]",
+					"blockers": [{"kind": "b", "on": "parts arrive"}]
+				}
+			]
+		}`,
+		string(b),
+	)
+
+	// round trip
+	rslt := new(TreeNode)
+	err = json.Unmarshal(b, rslt)
+	assert.Nil(err)
+	assert.Equal("fix sink [w plumber]", rslt.Referent)
+	assert.Equal(1, len(rslt.Children))
+	assert.Nil(rslt.Parent)
+	assert.Equal(rslt, rslt.Children[0].Parent)
+	assert.Equal(1, rslt.Children[0].Depth())
+}
@@ -0,0 +1,45 @@
+// Package exporter converts task lists into formats that can be read by people and programs that
+// don't know the basic format.
+//
+// The basic format is bottom-up: a node's children appear above it, in the order opposite to that
+// in which they're to be done. Every exporter here is top-down instead: each node is followed by
+// its children, in the order in which they're to be done (see common.TreeNode.Walk).
+package exporter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// Exporter returns the representation of taskList, the list identified by listName, in some
+// format.
+type Exporter func(listName string, taskList []*common.Task) ([]byte, error)
+
+// exporters maps the name of each supported format to its Exporter.
+var exporters = map[string]Exporter{
+	"markdown": Markdown,
+	"org":      Org,
+	"opml":     OPML,
+}
+
+// Formats returns the names of the supported formats, in lexical order.
+func Formats() []string {
+	rslt := make([]string, 0, len(exporters))
+	for name := range exporters {
+		rslt = append(rslt, name)
+	}
+	sort.Strings(rslt)
+	return rslt
+}
+
+// Export returns the representation of taskList, the list identified by listName, in the format
+// with the given name.
+func Export(format, listName string, taskList []*common.Task) ([]byte, error) {
+	exp, ok := exporters[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown export format `%s`", format)
+	}
+	return exp(listName, taskList)
+}
@@ -0,0 +1,22 @@
+package exporter
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	assert.Equal([]string{"markdown", "opml", "org"}, Formats())
+
+	b, err := Export("org", "make_pasta", common.MakePasta())
+	assert.Nil(err)
+	assert.Contains(string(b), "* TODO make pasta\n")
+
+	_, err = Export("docx", "make_pasta", common.MakePasta())
+	assert.NotNil(err)
+}
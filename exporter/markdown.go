@@ -0,0 +1,29 @@
+package exporter
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// Markdown represents taskList as a nested Markdown checklist, under a heading with the list's
+// name. For example:
+//
+//	# make_pasta
+//
+//	- [ ] make pasta
+//	  - [ ] boil water
+//	    - [ ] put water in pot
+func Markdown(listName string, taskList []*common.Task) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("# " + listName + "\n\n")
+	for _, t := range taskList {
+		t.RootNode.Walk(func(n *common.TreeNode) error {
+			b.WriteString(strings.Repeat("  ", n.Depth()))
+			b.WriteString("- [ ] " + n.Referent + "\n")
+			return nil
+		})
+	}
+	return b.Bytes(), nil
+}
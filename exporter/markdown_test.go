@@ -0,0 +1,29 @@
+package exporter
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdown(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	b, err := Markdown("make_pasta", common.MakePasta())
+	assert.Nil(err)
+	assert.Equal(
+		"# make_pasta\n"+
+			"\n"+
+			"- [ ] make pasta\n"+
+			"  - [ ] boil water\n"+
+			"    - [ ] put water in pot\n"+
+			"    - [ ] put pot on burner\n"+
+			"    - [ ] turn burner on\n"+
+			"  - [ ] put pasta in water\n"+
+			"  - [ ] [b cooked]\n"+
+			"  - [ ] drain pasta\n",
+		string(b),
+	)
+}
@@ -0,0 +1,58 @@
+package exporter
+
+import (
+	"encoding/xml"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// opmlOutline is an <outline> element in an OPML document.
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// opmlDocument is the root element of an OPML document.
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Title   string        `xml:"head>title"`
+	Body    []opmlOutline `xml:"body>outline"`
+}
+
+// opmlOutlineFor returns the <outline> element for the subtree rooted at n.
+func opmlOutlineFor(n *common.TreeNode) opmlOutline {
+	o := opmlOutline{Text: n.Referent}
+	for _, cn := range n.Children {
+		o.Outlines = append(o.Outlines, opmlOutlineFor(cn))
+	}
+	return o
+}
+
+// OPML represents taskList as an OPML 2.0 outline, with the list's name as the title. For example:
+//
+//	<?xml version="1.0" encoding="UTF-8"?>
+//	<opml version="2.0">
+//	  <head>
+//	    <title>make_pasta</title>
+//	  </head>
+//	  <body>
+//	    <outline text="make pasta">
+//	      <outline text="boil water">
+//	        <outline text="put water in pot"></outline>
+//	      </outline>
+//	    </outline>
+//	  </body>
+//	</opml>
+func OPML(listName string, taskList []*common.Task) ([]byte, error) {
+	doc := opmlDocument{Version: "2.0", Title: listName}
+	for _, t := range taskList {
+		doc.Body = append(doc.Body, opmlOutlineFor(t.RootNode))
+	}
+
+	b, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(xml.Header), b...), '\n'), nil
+}
@@ -0,0 +1,33 @@
+package exporter
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOPML(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	taskList := []*common.Task{common.NewTask(common.NewTreeNode(`pick up <milk> & "eggs"`))}
+	taskList = append(taskList, common.MultipleNested()[1])
+	b, err := OPML("groceries", taskList)
+	assert.Nil(err)
+	assert.Equal(
+		`<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+			`<opml version="2.0">`+"\n"+
+			`  <head>`+"\n"+
+			`    <title>groceries</title>`+"\n"+
+			`  </head>`+"\n"+
+			`  <body>`+"\n"+
+			`    <outline text="pick up &lt;milk&gt; &amp; &#34;eggs&#34;"></outline>`+"\n"+
+			`    <outline text="task 1">`+"\n"+
+			`      <outline text="subtask 1"></outline>`+"\n"+
+			`    </outline>`+"\n"+
+			`  </body>`+"\n"+
+			`</opml>`+"\n",
+		string(b),
+	)
+}
@@ -0,0 +1,29 @@
+package exporter
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// Org represents taskList as an Org-mode outline of TODO headlines, with the list's name as the
+// title. For example:
+//
+//	#+TITLE: make_pasta
+//
+//	* TODO make pasta
+//	** TODO boil water
+//	*** TODO put water in pot
+func Org(listName string, taskList []*common.Task) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("#+TITLE: " + listName + "\n\n")
+	for _, t := range taskList {
+		t.RootNode.Walk(func(n *common.TreeNode) error {
+			b.WriteString(strings.Repeat("*", n.Depth()+1))
+			b.WriteString(" TODO " + n.Referent + "\n")
+			return nil
+		})
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,26 @@
+package exporter
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrg(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	b, err := Org("multiple_nested", common.MultipleNested())
+	assert.Nil(err)
+	assert.Equal(
+		"#+TITLE: multiple_nested\n"+
+			"\n"+
+			"* TODO task 0\n"+
+			"** TODO subtask 0\n"+
+			"*** TODO subsubtask 0\n"+
+			"* TODO task 1\n"+
+			"** TODO subtask 1\n",
+		string(b),
+	)
+}
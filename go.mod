@@ -5,6 +5,6 @@ go 1.16
 require (
 	github.com/gin-gonic/gin v1.7.4 // indirect
 	github.com/sirupsen/logrus v1.8.1
-	github.com/stretchr/testify v1.7.0 // indirect
+	github.com/stretchr/testify v1.7.0
 	golang.org/x/sys v0.0.0-20210309074719-68d13333faf2 // indirect
 )
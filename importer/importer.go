@@ -0,0 +1,42 @@
+// Package importer converts task lists from other formats into Tasks.
+//
+// Every format here is top-down: each item is followed by its sub-items, in the order in which
+// they're to be done. So, the first item in the input becomes the first task in the list, and so
+// on (see common.TreeNode.Walk).
+package importer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// Importer parses b, a task list in some format.
+type Importer func(b []byte) ([]*common.Task, error)
+
+// importers maps the name of each supported format to its Importer.
+var importers = map[string]Importer{
+	"markdown": Markdown,
+	"opml":     OPML,
+	"todotxt":  TodoTxt,
+}
+
+// Formats returns the names of the supported formats, in lexical order.
+func Formats() []string {
+	rslt := make([]string, 0, len(importers))
+	for name := range importers {
+		rslt = append(rslt, name)
+	}
+	sort.Strings(rslt)
+	return rslt
+}
+
+// Import parses b, a task list in the format with the given name.
+func Import(format string, b []byte) ([]*common.Task, error) {
+	imp, ok := importers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown import format `%s`", format)
+	}
+	return imp(b)
+}
@@ -0,0 +1,39 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/danslimmon/impulse/exporter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	assert.Equal([]string{"markdown", "opml", "todotxt"}, Formats())
+
+	taskList, err := Import("todotxt", []byte("drain pasta\n"))
+	assert.Nil(err)
+	assert.Equal(1, len(taskList))
+
+	_, err = Import("xlsx", []byte("drain pasta\n"))
+	assert.NotNil(err)
+}
+
+// Tests that what we export, we can import.
+func TestImport_RoundTrip(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	for _, format := range []string{"markdown", "opml"} {
+		b, err := exporter.Export(format, "make_pasta", common.MakePasta())
+		assert.Nil(err)
+		taskList, err := Import(format, b)
+		assert.Nil(err)
+		if assert.Equal(1, len(taskList), format) {
+			assert.True(common.MakePasta()[0].Equal(taskList[0]), format)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package importer
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// markdownTabWidth is the number of columns a tab counts for when comparing the indentation of
+// list items.
+const markdownTabWidth = 4
+
+// markdownItemPattern matches a Markdown list item, e.g. `- [ ] boil water` or `2. drain pasta`.
+// The groups are the indentation, the checkbox's contents (if there's a checkbox), and the item's
+// text.
+var markdownItemPattern = regexp.MustCompile(`^(\s*)(?:[-*+]|\d+[.)])\s+(?:\[([ xX])\]\s+)?(.*)$`)
+
+// markdownIndent returns the width of the given indentation, in columns.
+func markdownIndent(s string) int {
+	return len(strings.Replace(s, "\t", strings.Repeat(" ", markdownTabWidth), -1))
+}
+
+// Markdown parses the lists in a Markdown document, such as a nested checklist:
+//
+//	## dinner
+//	- [ ] make pasta
+//	  - [ ] boil water
+//	    - [x] put water in pot
+//	    - [ ] put pot on burner
+//
+// Each item becomes a node, and sub-items become its children. Items that are checked off are
+// skipped, along with their sub-items. Lines that aren't list items (e.g. headings) are ignored.
+func Markdown(b []byte) ([]*common.Task, error) {
+	type frame struct {
+		indent int
+		node   *common.TreeNode
+	}
+
+	root := common.NewTreeNode("")
+	stack := []frame{frame{indent: -1, node: root}}
+	// skipIndent is the indentation of the checked-off item whose sub-items we're skipping, or -1
+	// if we're not skipping anything.
+	skipIndent := -1
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		m := markdownItemPattern.FindStringSubmatch(strings.TrimRight(string(line), " \t\r"))
+		if m == nil {
+			continue
+		}
+		indent := markdownIndent(m[1])
+		if skipIndent >= 0 {
+			if indent > skipIndent {
+				continue
+			}
+			skipIndent = -1
+		}
+		if m[2] == "x" || m[2] == "X" {
+			skipIndent = indent
+			continue
+		}
+
+		for stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		n := common.NewTreeNode(m[3])
+		stack[len(stack)-1].node.AddChild(n)
+		stack = append(stack, frame{indent: indent, node: n})
+	}
+	return tasksFromRoot(root), nil
+}
+
+// tasksFromRoot returns a task for each child of root, a placeholder node.
+func tasksFromRoot(root *common.TreeNode) []*common.Task {
+	rslt := make([]*common.Task, 0, len(root.Children))
+	for _, n := range root.Children {
+		n.Parent = nil
+		rslt = append(rslt, common.NewTask(n))
+	}
+	return rslt
+}
@@ -0,0 +1,40 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdown(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	b := []byte("# chores\n" +
+		"\n" +
+		"Some prose that isn't a list item.\n" +
+		"\n" +
+		"* [ ] fix sink\n" +
+		"    * [ ] buy wrench\n" +
+		"\t* [x] call plumber\n" +
+		"\t\t* [ ] look up number\n" +
+		"    * [ ] turn off water\n" +
+		"- [X] mow lawn\n" +
+		"1. write report #work\n" +
+		"   2. outline\n")
+	taskList, err := Markdown(b)
+	assert.Nil(err)
+
+	fixSink := common.NewTreeNode("fix sink")
+	fixSink.AddChild(common.NewTreeNode("buy wrench"))
+	fixSink.AddChild(common.NewTreeNode("turn off water"))
+	report := common.NewTreeNode("write report #work")
+	report.AddChild(common.NewTreeNode("outline"))
+
+	if assert.Equal(2, len(taskList)) {
+		assert.True(common.NewTask(fixSink).Equal(taskList[0]))
+		assert.True(common.NewTask(report).Equal(taskList[1]))
+		assert.Nil(taskList[0].RootNode.Parent)
+	}
+}
@@ -0,0 +1,52 @@
+package importer
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// opmlOutline is an <outline> element in an OPML document.
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Status   string        `xml:"_status,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// opmlDocument is the root element of an OPML document.
+type opmlDocument struct {
+	Body []opmlOutline `xml:"body>outline"`
+}
+
+// opmlNodes returns the nodes for the given outlines, skipping those that are checked off (as
+// indicated by the `_status="checked"` attribute some outliners use) along with their children.
+func opmlNodes(outlines []opmlOutline) []*common.TreeNode {
+	rslt := make([]*common.TreeNode, 0, len(outlines))
+	for _, o := range outlines {
+		if o.Status == "checked" {
+			continue
+		}
+		n := common.NewTreeNode(strings.Join(strings.Fields(o.Text), " "))
+		for _, cn := range opmlNodes(o.Outlines) {
+			n.AddChild(cn)
+		}
+		rslt = append(rslt, n)
+	}
+	return rslt
+}
+
+// OPML parses an OPML outline. Each <outline> element becomes a node, and the elements nested
+// within it become its children.
+func OPML(b []byte) ([]*common.Task, error) {
+	var doc opmlDocument
+	if err := xml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	rslt := make([]*common.Task, 0, len(doc.Body))
+	for _, n := range opmlNodes(doc.Body) {
+		rslt = append(rslt, common.NewTask(n))
+	}
+	return rslt, nil
+}
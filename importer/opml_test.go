@@ -0,0 +1,41 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOPML(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	b := []byte(`<?xml version="1.0"?>
+<opml version="1.0">
+  <head><title>chores</title></head>
+  <body>
+    <outline text="fix sink">
+      <outline text="buy &lt;wrench&gt;"/>
+      <outline text="call plumber" _status="checked">
+        <outline text="look up number"/>
+      </outline>
+    </outline>
+    <outline text="mow
+      lawn"/>
+  </body>
+</opml>
+`)
+	taskList, err := OPML(b)
+	assert.Nil(err)
+
+	fixSink := common.NewTreeNode("fix sink")
+	fixSink.AddChild(common.NewTreeNode("buy <wrench>"))
+	if assert.Equal(2, len(taskList)) {
+		assert.True(common.NewTask(fixSink).Equal(taskList[0]))
+		assert.Equal("mow lawn", taskList[1].RootNode.Referent)
+	}
+
+	_, err = OPML([]byte("<opml><body><outline text=\"fix sink\">"))
+	assert.NotNil(err)
+}
@@ -0,0 +1,46 @@
+package importer
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+var (
+	// todoTxtPrefixPattern matches the optional priority and creation date at the beginning of a
+	// todo.txt line, e.g. `(A) 2026-10-19 `.
+	todoTxtPrefixPattern = regexp.MustCompile(`^(\([A-Z]\) )?(\d{4}-\d{2}-\d{2} )?`)
+	// todoTxtTagPattern matches a todo.txt project (`+garden`) or context (`@phone`).
+	todoTxtTagPattern = regexp.MustCompile(`^[+@]([\p{L}\p{N}_/-]+)$`)
+)
+
+// TodoTxt parses a todo.txt file (see http://todotxt.org). Each line becomes a task with no
+// subtasks.
+//
+// Completed tasks (those starting with `x `) are skipped. The priority and creation date are
+// dropped, and projects and contexts become tags, so that
+//
+//	(A) 2026-10-19 call plumber +house @phone due:2026-10-21
+//
+// becomes `call plumber #house #phone due:2026-10-21`.
+func TodoTxt(b []byte) ([]*common.Task, error) {
+	rslt := make([]*common.Task, 0)
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		s := strings.TrimSpace(string(line))
+		if s == "" || strings.HasPrefix(s, "x ") {
+			continue
+		}
+		s = todoTxtPrefixPattern.ReplaceAllString(s, "")
+
+		words := strings.Fields(s)
+		for i, w := range words {
+			if m := todoTxtTagPattern.FindStringSubmatch(w); m != nil {
+				words[i] = "#" + m[1]
+			}
+		}
+		rslt = append(rslt, common.NewTask(common.NewTreeNode(strings.Join(words, " "))))
+	}
+	return rslt, nil
+}
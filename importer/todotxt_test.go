@@ -0,0 +1,31 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTodoTxt(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	b := []byte("(A) 2026-10-19 call plumber +house @phone due:2026-10-21\n" +
+		"x 2026-10-18 2026-10-01 mow lawn\n" +
+		"\n" +
+		"2026-10-19 email +Big.Project re: budget\n" +
+		"water plants\n")
+	taskList, err := TodoTxt(b)
+	assert.Nil(err)
+
+	referents := make([]string, 0)
+	for _, t := range taskList {
+		assert.Equal(0, len(t.RootNode.Children))
+		referents = append(referents, t.RootNode.Referent)
+	}
+	assert.Equal([]string{
+		"call plumber #house #phone due:2026-10-21",
+		"email +Big.Project re: budget",
+		"water plants",
+	}, referents)
+}
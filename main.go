@@ -1,10 +1,17 @@
 package main
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/danslimmon/impulse/analytics"
 	"github.com/danslimmon/impulse/client"
 	"github.com/danslimmon/impulse/common"
 	"github.com/danslimmon/impulse/server"
@@ -19,41 +26,741 @@ func main() {
 	addr := "127.0.0.1:30271"
 	ds := server.NewFilesystemDatastore(dataDir)
 	ts := server.NewBasicTaskstore(ds)
+	// IMPULSE_INDENT sets the indent unit (`tab`, `2`, or `4`) for new lists. Existing lists keep
+	// whatever unit they're already indented with.
+	if s := os.Getenv("IMPULSE_INDENT"); s != "" {
+		unit, err := server.ParseIndent(s)
+		if err != nil {
+			panic("error: IMPULSE_INDENT: " + err.Error())
+		}
+		ts.SetIndent(unit)
+	}
 	apiServer := server.NewServer(ts)
 	if err := apiServer.Start(addr); err != nil {
 		panic("failed to start test server on " + addr + ": " + err.Error())
 	}
 
 	apiClient := client.NewClient(addr)
+	// IMPULSE_CLIENT names this client, so that its hoist (see `impulse hoist`) is kept separate from
+	// those of other clients.
+	clientName := os.Getenv("IMPULSE_CLIENT")
+	if clientName == "" {
+		clientName = "cli"
+	}
+	// Wherever a command takes a list name followed by a fixed number of arguments, the list name may
+	// be left off, in which case the active list (see `impulse switch`) is used.
 	switch os.Args[1] {
 	case "show":
-		resp, err := apiClient.GetTaskList(os.Args[2])
+		// Any arguments after the list name are metadata tokens (e.g. `#work` or `due:2026-11-01`)
+		// that nodes must satisfy in order to be shown, or `--all` to show snoozed nodes too.
+		args := os.Args[2:]
+		if len(args) == 0 || isFilterArg(args[0]) {
+			args = append([]string{activeList(apiClient)}, args...)
+		}
+		resp, err := apiClient.GetTaskList(args[0])
 		if err != nil {
-			panic(fmt.Sprintf("failed to get task list `%s`: %s", os.Args[2], err.Error()))
+			panic(fmt.Sprintf("failed to get task list `%s`: %s", args[0], describeError(err)))
 		}
 
-		for _, t := range resp.Result {
+		showAll := false
+		filterArgs := make([]string, 0)
+		for _, arg := range args[1:] {
+			if arg == "--all" {
+				showAll = true
+			} else {
+				filterArgs = append(filterArgs, arg)
+			}
+		}
+		if len(resp.Result) == 0 {
+			fmt.Println("empty stack")
+			return
+		}
+
+		// If a node in the list is hoisted, only its subtree is shown, under a breadcrumb of its
+		// ancestors.
+		taskList := resp.Result
+		if h := hoisted(apiClient, clientName, args[0]); h != nil {
+			fmt.Printf("[%s]\n", strings.Join(h.Hoist.Path, " > "))
+			taskList = []*common.Task{h.Task}
+		}
+		filter, _ := common.ParseMetadata(strings.Join(filterArgs, " "))
+		now := time.Now()
+		for _, t := range taskList {
 			t.RootNode.WalkFromTop(func(n *common.TreeNode) error {
+				if !n.Metadata().Satisfies(filter) {
+					return nil
+				}
+				if !showAll && n.Hidden(now) {
+					return nil
+				}
 				fmt.Printf(
 					"%s%v\n",
 					strings.Repeat("    ", n.Depth()),
 					n.Referent,
 				)
+				if n.Note != "" {
+					for _, l := range strings.Split(n.Note, "\n") {
+						fmt.Printf("%s  | %s\n", strings.Repeat("    ", n.Depth()), l)
+					}
+				}
 				return nil
 			})
 		}
 	case "archive":
-		lineID := common.GetLineID(os.Args[2], os.Args[3])
+		args := listArgs(apiClient, 1)
+		lineID := resolveLine(apiClient, args[0], args[1])
 		_, err := apiClient.ArchiveLine(lineID)
 		if err != nil {
-			panic(fmt.Sprintf("failed to archive line with ID `%s`: %s", os.Args[2], err.Error()))
+			panic(fmt.Sprintf("failed to archive line with ID `%s`: %s", lineID, err.Error()))
 		}
 	case "insert":
-		lineID := common.LineID(fmt.Sprintf("%s:%s", os.Args[2], os.Args[3]))
-		text := os.Args[4]
+		args := listArgs(apiClient, 2)
+		lineID := common.LineID(fmt.Sprintf("%s:%s", args[0], args[1]))
+		text := args[2]
 		_, err := apiClient.InsertTask(lineID, common.NewTask(common.NewTreeNode(text)))
 		if err != nil {
 			panic(fmt.Sprintf("failed to insert task: %s", err.Error()))
 		}
+	case "move":
+		// `impulse move <list> <line> <dest list> [<dest line>]` moves the line, along with its
+		// subtasks, to the top of the destination list, or after the given line.
+		//
+		// Since the destination line is optional, the source list can't be: `move a b c` could
+		// otherwise mean either list `a`, or the active list with destination line `c`.
+		args := os.Args[2:]
+		if len(args) != 3 && len(args) != 4 {
+			panic("usage: impulse move <list> <line> <dest list> [<dest line>]")
+		}
+		lineID := resolveLine(apiClient, args[0], args[1])
+		dest := common.LineID(args[2] + ":0")
+		if len(args) > 3 {
+			dest = resolveLine(apiClient, args[2], args[3])
+		}
+		if _, err := apiClient.MoveToList(lineID, dest); err != nil {
+			panic(fmt.Sprintf("failed to move line with ID `%s`: %s", lineID, describeError(err)))
+		}
+	case "top":
+		args := listArgs(apiClient, 0)
+		resp, err := apiClient.GetTaskList(args[0])
+		if err != nil {
+			panic(fmt.Sprintf("failed to get task list `%s`: %s", args[0], describeError(err)))
+		}
+
+		if len(resp.Result) == 0 {
+			fmt.Println("empty stack")
+			return
+		}
+		taskList := resp.Result
+		if h := hoisted(apiClient, clientName, args[0]); h != nil {
+			taskList = []*common.Task{h.Task}
+		}
+		top := common.Top(taskList)
+		if top == nil {
+			fmt.Println("everything is blocked")
+			return
+		}
+		fmt.Println(top.Referent)
+	case "push":
+		// `impulse push [<list>] <text>` puts a task on top of the list, or on top of the hoisted
+		// subtree.
+		args := listArgs(apiClient, 1)
+		if _, err := apiClient.Push(clientName, args[0], common.NewTask(common.NewTreeNode(args[1]))); err != nil {
+			panic(fmt.Sprintf("failed to push onto list `%s`: %s", args[0], describeError(err)))
+		}
+	case "pop":
+		// `impulse pop [<list>]` archives the top frame of the list, or of the hoisted subtree.
+		args := listArgs(apiClient, 0)
+		resp, err := apiClient.Pop(clientName, args[0])
+		if err != nil {
+			panic(fmt.Sprintf("failed to pop from list `%s`: %s", args[0], describeError(err)))
+		}
+		fmt.Println(strings.Join(resp.Path, " > "))
+	case "hoist":
+		// `impulse hoist [<list>] <line>` zooms into the line's subtree: `show`, `top`, `push`, and
+		// `pop` act on it alone until `impulse unhoist`.
+		args := listArgs(apiClient, 1)
+		lineID := resolveLine(apiClient, args[0], args[1])
+		if _, err := apiClient.SetHoist(clientName, lineID); err != nil {
+			panic(fmt.Sprintf("failed to hoist line with ID `%s`: %s", lineID, describeError(err)))
+		}
+	case "unhoist":
+		if _, err := apiClient.Unhoist(clientName); err != nil {
+			panic(fmt.Sprintf("failed to unhoist: %s", err.Error()))
+		}
+	case "unblock":
+		args := listArgs(apiClient, 1)
+		lineID := resolveLine(apiClient, args[0], args[1])
+		_, err := apiClient.Unblock(lineID)
+		if err != nil {
+			panic(fmt.Sprintf("failed to unblock line with ID `%s`: %s", lineID, err.Error()))
+		}
+	case "focus":
+		// `impulse focus [<list>] <duration>` starts a session; `impulse focus stop` abandons it.
+		// Either way, unless the session is over, we show a countdown.
+		if len(os.Args) > 2 && os.Args[2] == "stop" {
+			if _, err := apiClient.StopFocus(); err != nil {
+				panic(fmt.Sprintf("failed to stop focus session: %s", err.Error()))
+			}
+			return
+		}
+		if len(os.Args) > 2 {
+			args := listArgs(apiClient, 1)
+			d, err := time.ParseDuration(args[1])
+			if err != nil {
+				panic(fmt.Sprintf("invalid duration `%s`: %s", args[1], err.Error()))
+			}
+			if _, err := apiClient.StartFocus(args[0], d); err != nil {
+				panic(fmt.Sprintf("failed to start focus session: %s", describeError(err)))
+			}
+		}
+
+		for {
+			resp, err := apiClient.GetFocus()
+			if err != nil {
+				panic(fmt.Sprintf("failed to get focus session: %s", err.Error()))
+			}
+			if resp.Session == nil {
+				fmt.Println("\nno focus session in progress")
+				return
+			}
+			fmt.Printf(
+				"\r%8s  %s",
+				resp.Remaining.Round(time.Second),
+				resp.Session.Path[len(resp.Session.Path)-1],
+			)
+			time.Sleep(time.Second)
+		}
+	case "snooze":
+		args := listArgs(apiClient, 2)
+		lineID := resolveLine(apiClient, args[0], args[1])
+		d, err := time.ParseDuration(args[2])
+		if err != nil {
+			panic(fmt.Sprintf("invalid duration `%s`: %s", args[2], err.Error()))
+		}
+		_, err = apiClient.Snooze(lineID, time.Now().Add(d))
+		if err != nil {
+			panic(fmt.Sprintf("failed to snooze line with ID `%s`: %s", lineID, err.Error()))
+		}
+	case "recur":
+		// `impulse recur <list>` lists the list's recurrences, `impulse recur <list> <schedule>
+		// <text>` adds one, and `impulse recur pause|resume|delete <id>` manages an existing one.
+		switch {
+		case len(os.Args) == 4 && os.Args[2] == "pause":
+			if _, err := apiClient.SetRecurrencePaused(os.Args[3], true); err != nil {
+				panic(fmt.Sprintf("failed to pause recurrence `%s`: %s", os.Args[3], err.Error()))
+			}
+		case len(os.Args) == 4 && os.Args[2] == "resume":
+			if _, err := apiClient.SetRecurrencePaused(os.Args[3], false); err != nil {
+				panic(fmt.Sprintf("failed to resume recurrence `%s`: %s", os.Args[3], err.Error()))
+			}
+		case len(os.Args) == 4 && os.Args[2] == "delete":
+			if _, err := apiClient.DeleteRecurrence(os.Args[3]); err != nil {
+				panic(fmt.Sprintf("failed to delete recurrence `%s`: %s", os.Args[3], err.Error()))
+			}
+		case len(os.Args) == 4 || len(os.Args) == 5:
+			args := listArgs(apiClient, 2)
+			template := common.NewTask(common.NewTreeNode(args[2]))
+			resp, err := apiClient.AddRecurrence(args[0], args[1], template)
+			if err != nil {
+				panic(fmt.Sprintf("failed to add recurrence: %s", err.Error()))
+			}
+			fmt.Printf("added recurrence %s\n", resp.Recurrence.ID)
+		default:
+			listName := ""
+			if len(os.Args) > 2 {
+				listName = os.Args[2]
+			}
+			resp, err := apiClient.GetRecurrences(listName)
+			if err != nil {
+				panic(fmt.Sprintf("failed to get recurrences: %s", err.Error()))
+			}
+
+			for _, rec := range resp.Recurrences {
+				status := ""
+				if rec.Paused {
+					status = " (paused)"
+				}
+				fmt.Printf(
+					"%4s  %-12s  %-20s  %s%s\n",
+					rec.ID,
+					rec.ListName,
+					rec.Schedule,
+					rec.Template.RootNode.Referent,
+					status,
+				)
+			}
+		}
+	case "search":
+		// `impulse search [-i|-r] [--history] <query>` searches all lists. -i ignores case, -r
+		// treats the query as a regular expression, and --history searches archived lines too.
+		mode := server.SearchSubstring
+		history := false
+		args := os.Args[2:]
+		for len(args) > 1 {
+			if args[0] == "-i" {
+				mode = server.SearchCaseInsensitive
+			} else if args[0] == "-r" {
+				mode = server.SearchRegex
+			} else if args[0] == "--history" {
+				history = true
+			} else {
+				break
+			}
+			args = args[1:]
+		}
+		query := strings.Join(args, " ")
+		resp, err := apiClient.Search(query, mode, history)
+		if err != nil {
+			panic(fmt.Sprintf("failed to search for `%s`: %s", query, err.Error()))
+		}
+
+		for _, r := range resp.Results {
+			if r.ListName == "history" {
+				fmt.Printf("history (archived %s): %s\n", r.Time.Format("2006-01-02T15:04:05"), r.Path[0])
+				continue
+			}
+			fmt.Printf("%s: %s\n", r.ListName, strings.Join(r.Path, " > "))
+		}
+	case "query":
+		// `impulse query <list> <query>` queries one list; `impulse query - <query>` queries them
+		// all. If the first argument isn't a list name, the whole thing is the query.
+		args := os.Args[2:]
+		if args[0] != "-" && !isList(apiClient, args[0]) {
+			args = append([]string{activeList(apiClient)}, args...)
+		}
+		listName := args[0]
+		if listName == "-" {
+			listName = ""
+		}
+		query := strings.Join(args[1:], " ")
+		resp, err := apiClient.Query(listName, query)
+		if err != nil {
+			panic(fmt.Sprintf("failed to run query `%s`: %s", query, describeError(err)))
+		}
+
+		for _, r := range resp.Results {
+			fmt.Printf("%s: %s\n", r.ListName, strings.Join(r.Path, " > "))
+		}
+	case "export":
+		// `impulse export [--format=<format>] <list>` writes the list to stdout, by default as
+		// Markdown.
+		format := "markdown"
+		args := os.Args[2:]
+		if len(args) > 0 && strings.HasPrefix(args[0], "--format=") {
+			format = strings.TrimPrefix(args[0], "--format=")
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			args = []string{activeList(apiClient)}
+		}
+		resp, err := apiClient.Export(args[0], format)
+		if err != nil {
+			panic(fmt.Sprintf("failed to export task list `%s` as %s: %s", args[0], format, describeError(err)))
+		}
+		fmt.Print(resp.Data)
+	case "import":
+		// `impulse import [--format=<format>] <file> <list>` adds the tasks in the file to the end
+		// of the list, creating the list if need be. The file is assumed to be Markdown unless
+		// otherwise specified.
+		format := "markdown"
+		args := os.Args[2:]
+		if len(args) > 1 && strings.HasPrefix(args[0], "--format=") {
+			format = strings.TrimPrefix(args[0], "--format=")
+			args = args[1:]
+		}
+		if len(args) == 1 {
+			args = append(args, activeList(apiClient))
+		}
+		b, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			panic(fmt.Sprintf("failed to read `%s`: %s", args[0], err.Error()))
+		}
+		resp, err := apiClient.Import(args[1], format, string(b))
+		if err != nil {
+			panic(fmt.Sprintf("failed to import `%s` into task list `%s`: %s", args[0], args[1], describeError(err)))
+		}
+		fmt.Printf("imported %d tasks into %s\n", resp.Imported, args[1])
+	case "fsck":
+		// `impulse fsck [--repair] <list>` reports the problems with the list's data. With --repair,
+		// it shows the changes it would make to fix them, and makes them if the user agrees.
+		args := os.Args[2:]
+		repair := len(args) > 0 && args[0] == "--repair"
+		if repair {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			args = []string{activeList(apiClient)}
+		}
+		listName := args[0]
+		resp, err := apiClient.CheckList(listName)
+		if err != nil {
+			panic(fmt.Sprintf("failed to check task list `%s`: %s", listName, err.Error()))
+		}
+
+		for _, p := range resp.Result.Problems {
+			fmt.Printf("%s:%d: %s\n", listName, p.Line, p.Description)
+		}
+		if !repair || len(resp.Result.Diff) == 0 {
+			return
+		}
+		fmt.Println()
+		for _, line := range resp.Result.Diff {
+			fmt.Println(line)
+		}
+		fmt.Print("\napply these changes? [y/N] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.TrimSpace(strings.ToLower(answer)) != "y" {
+			return
+		}
+		if _, err := apiClient.RepairList(listName, resp.StateID); err != nil {
+			panic(fmt.Sprintf("failed to repair task list `%s`: %s", listName, err.Error()))
+		}
+	case "new":
+		if _, err := apiClient.CreateList(os.Args[2]); err != nil {
+			panic(fmt.Sprintf("failed to create list `%s`: %s", os.Args[2], err.Error()))
+		}
+	case "note":
+		// `impulse note <list> <line>` opens the line's note in $EDITOR, and saves it if it's been
+		// changed.
+		args := listArgs(apiClient, 1)
+		lineID := resolveLine(apiClient, args[0], args[1])
+		resp, err := apiClient.GetNote(lineID)
+		if err != nil {
+			panic(fmt.Sprintf("failed to get note for line with ID `%s`: %s", lineID, err.Error()))
+		}
+
+		f, err := ioutil.TempFile("", "impulse-note-*.txt")
+		if err != nil {
+			panic(fmt.Sprintf("failed to create temp file: %s", err.Error()))
+		}
+		defer os.Remove(f.Name())
+		if resp.Note != "" {
+			f.WriteString(resp.Note + "\n")
+		}
+		f.Close()
+
+		editor := strings.Fields(os.Getenv("EDITOR"))
+		if len(editor) == 0 {
+			editor = []string{"vi"}
+		}
+		cmd := exec.Command(editor[0], append(editor[1:], f.Name())...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			panic(fmt.Sprintf("failed to run editor: %s", err.Error()))
+		}
+
+		b, err := ioutil.ReadFile(f.Name())
+		if err != nil {
+			panic(fmt.Sprintf("failed to read note: %s", err.Error()))
+		}
+		note := strings.TrimRight(string(b), "\n")
+		if note == resp.Note {
+			return
+		}
+		if _, err := apiClient.SetNote(lineID, note); err != nil {
+			panic(fmt.Sprintf("failed to save note for line with ID `%s`: %s", lineID, err.Error()))
+		}
+	case "switch":
+		// `impulse switch <list>` makes the list the active one.
+		if _, err := apiClient.SetActiveList(os.Args[2]); err != nil {
+			panic(fmt.Sprintf("failed to switch to list `%s`: %s", os.Args[2], describeError(err)))
+		}
+	case "stacks":
+		// `impulse stacks` shows the stack of stacks: each list with its top frame. The active list
+		// is marked with a `*`.
+		resp, err := apiClient.GetStacks()
+		if err != nil {
+			panic(fmt.Sprintf("failed to get stacks: %s", err.Error()))
+		}
+
+		for _, s := range resp.Stacks {
+			marker := " "
+			if s.Active {
+				marker = "*"
+			}
+			top := strings.Join(s.Top, " > ")
+			switch {
+			case s.Error != "":
+				top = "error: " + s.Error
+			case s.Empty:
+				top = "empty stack"
+			case len(s.Top) == 0:
+				top = "everything is blocked"
+			}
+			fmt.Printf("%s %-20s  %s\n", marker, s.ListName, top)
+		}
+	case "time":
+		// Group by node unless told otherwise
+		by := "node"
+		if len(os.Args) > 2 {
+			by = os.Args[2]
+		}
+		resp, err := apiClient.GetTimeReport(by)
+		if err != nil {
+			panic(fmt.Sprintf("failed to get time report by %s: %s", by, err.Error()))
+		}
+
+		for _, e := range resp.Entries {
+			fmt.Printf("%10s  %s\n", e.Duration.Round(time.Second), e.Key)
+		}
+	case "stats":
+		// `impulse stats [<days>]` shows productivity statistics for the last 14 days, or however many
+		// are given.
+		days := 14
+		if len(os.Args) > 2 {
+			n, err := strconv.Atoi(os.Args[2])
+			if err != nil {
+				panic(fmt.Sprintf("invalid number of days `%s`", os.Args[2]))
+			}
+			days = n
+		}
+		resp, err := apiClient.GetStats(days)
+		if err != nil {
+			panic(fmt.Sprintf("failed to get stats: %s", err.Error()))
+		}
+		printStats(resp.Stats)
+	case "interrupts":
+		// `impulse interrupts` shows, for each frame that's been interrupted, how often and for how
+		// long, and what the interrupts were.
+		resp, err := apiClient.GetInterruptReport()
+		if err != nil {
+			panic(fmt.Sprintf("failed to get interrupt report: %s", err.Error()))
+		}
+
+		for _, s := range resp.Summaries {
+			fmt.Printf("%s: %s\n", s.ListName, strings.Join(s.Frame, " > "))
+			fmt.Printf("    %d interrupts, %s\n", s.Count, s.Duration.Round(time.Second))
+			for _, k := range s.Kinds {
+				fmt.Printf("    %5d  %s\n", k.Count, k.Kind)
+			}
+		}
+	case "review":
+		// `impulse review [<list>] [<days>]` walks through the nodes that haven't been touched in the
+		// given number of days (14 by default), oldest first, asking what to do with each.
+		listName := ""
+		days := 14
+		for _, arg := range os.Args[2:] {
+			if n, err := strconv.Atoi(arg); err == nil {
+				days = n
+			} else {
+				listName = arg
+			}
+		}
+		resp, err := apiClient.GetStaleNodes(listName, time.Duration(days)*24*time.Hour)
+		if err != nil {
+			panic(fmt.Sprintf("failed to get stale nodes: %s", describeError(err)))
+		}
+		if len(resp.Nodes) == 0 {
+			fmt.Println("nothing to review")
+			return
+		}
+
+		stdin := bufio.NewReader(os.Stdin)
+		for _, n := range resp.Nodes {
+			age := time.Since(n.LastTouched).Round(time.Hour)
+			fmt.Printf("\n%s: %s\n(untouched for %d days)\n", n.ListName, strings.Join(n.Path, " > "), int(age.Hours()/24))
+			if !reviewNode(apiClient, stdin, n) {
+				return
+			}
+		}
+	case "watch":
+		args := listArgs(apiClient, 0)
+		events, _, err := apiClient.Subscribe(args[0])
+		if err != nil {
+			panic(fmt.Sprintf("failed to subscribe to task list `%s`: %s", args[0], err.Error()))
+		}
+
+		for ev := range events {
+			fmt.Printf(
+				"%s %s %s %s %s\n",
+				ev.Time.Format("2006-01-02T15:04:05"),
+				ev.ListName,
+				ev.Op,
+				ev.LineID,
+				ev.StateID,
+			)
+		}
+	}
+}
+
+// activeList returns the name of the active list, for use when a command's list name is left off.
+func activeList(apiClient *client.Client) string {
+	resp, err := apiClient.GetActiveList()
+	if err != nil {
+		panic(fmt.Sprintf("failed to get active list: %s", err.Error()))
+	}
+	if resp.ListName == "" {
+		panic("error: no list given, and there's no active list (see `impulse switch`)")
+	}
+	return resp.ListName
+}
+
+// resolveLine returns the ID of the line in the list identified by listName whose text is text, as
+// the user has typed or pasted it from the list's file.
+func resolveLine(apiClient *client.Client, listName, text string) common.LineID {
+	resp, err := apiClient.ResolveLine(listName, text)
+	if err != nil {
+		panic(fmt.Sprintf("failed to find line `%s` in list `%s`: %s", text, listName, describeError(err)))
+	}
+	return resp.LineID
+}
+
+// listArgs returns the arguments of a command that takes a list name followed by n more arguments.
+// If the list name has been left off, the active list's name is put in its place.
+//
+// Only commands whose argument count is fixed may use listArgs; otherwise there'd be no telling
+// whether the list name had been left off.
+func listArgs(apiClient *client.Client, n int) []string {
+	args := os.Args[2:]
+	if len(args) == n {
+		args = append([]string{activeList(apiClient)}, args...)
 	}
+	if len(args) != n+1 {
+		panic(fmt.Sprintf("error: wrong number of arguments to `impulse %s`", os.Args[1]))
+	}
+	return args
+}
+
+// hoisted returns the hoist belonging to the client named clientName, along with the hoisted
+// subtree, if the hoisted node is in the list identified by listName. Otherwise it returns nil.
+func hoisted(apiClient *client.Client, clientName, listName string) *server.GetHoistResponse {
+	resp, err := apiClient.GetHoist(clientName)
+	if err != nil {
+		panic(fmt.Sprintf("failed to get hoist: %s", err.Error()))
+	}
+	if resp.Hoist == nil || resp.Hoist.ListName != listName {
+		return nil
+	}
+	return resp
+}
+
+// reviewNode asks the user what to do with n, a stale node, and does it: keep it (by touching it),
+// archive it along with its descendants (which are stale too, so aren't offered separately),
+// snooze it, or move it to another list. It returns false if the user wants to stop
+// reviewing.
+func reviewNode(apiClient *client.Client, stdin *bufio.Reader, n server.StaleNode) bool {
+	for {
+		fmt.Print("[k]eep, [a]rchive, [s]nooze <duration>, [m]ove <list>, or [q]uit? ")
+		answer, err := stdin.ReadString('\n')
+		if err != nil {
+			return false
+		}
+		fields := strings.Fields(answer)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch {
+		case fields[0] == "k":
+			_, err = apiClient.Touch(n.LineID)
+		case fields[0] == "a":
+			_, err = apiClient.ArchiveSubtree(n.LineID)
+		case fields[0] == "s" && len(fields) == 2:
+			d, parseErr := time.ParseDuration(fields[1])
+			if parseErr != nil {
+				fmt.Printf("invalid duration `%s`\n", fields[1])
+				continue
+			}
+			_, err = apiClient.Snooze(n.LineID, time.Now().Add(d))
+		case fields[0] == "m" && len(fields) == 2:
+			_, err = apiClient.MoveToList(n.LineID, common.LineID(fields[1]+":0"))
+		case fields[0] == "q":
+			return false
+		default:
+			continue
+		}
+		if err != nil {
+			fmt.Printf("error: %s\n", describeError(err))
+			continue
+		}
+		return true
+	}
+}
+
+// isList determines whether name is the name of a list.
+func isList(apiClient *client.Client, name string) bool {
+	resp, err := apiClient.GetStacks()
+	if err != nil {
+		panic(fmt.Sprintf("failed to get stacks: %s", err.Error()))
+	}
+	for _, s := range resp.Stacks {
+		if s.ListName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isFilterArg determines whether arg is one of the arguments to `impulse show` that come after the
+// list name: a metadata token or `--all`.
+func isFilterArg(arg string) bool {
+	_, text := common.ParseMetadata(arg)
+	return arg == "--all" || text == ""
+}
+
+// printStats prints stats as sparklines followed by tables by day, week, and list.
+func printStats(stats *analytics.Stats) {
+	done := make([]float64, len(stats.Days))
+	maxDepth := make([]float64, len(stats.Days))
+	avgDepth := make([]float64, len(stats.Days))
+	for i, d := range stats.Days {
+		done[i] = float64(d.Completions)
+		maxDepth[i] = float64(d.MaxDepth)
+		avgDepth[i] = d.AvgDepth
+	}
+	fmt.Printf("completions  %s  %d in %d days\n", analytics.Sparkline(done), stats.Completions, len(stats.Days))
+	fmt.Printf("max depth    %s  %d\n", analytics.Sparkline(maxDepth), stats.MaxDepth)
+	fmt.Printf("avg depth    %s  %.1f\n", analytics.Sparkline(avgDepth), stats.AvgDepth)
+	fmt.Printf("average time from push to archive: %s\n", formatStatsDuration(stats.AvgTimeToArchive))
+
+	fmt.Printf("\n%-14s  %5s  %9s  %9s\n", "day", "done", "max depth", "avg depth")
+	for _, d := range stats.Days {
+		fmt.Printf("%-14s  %5d  %9d  %9.1f\n", d.Date.Format("Mon 2006-01-02"), d.Completions, d.MaxDepth, d.AvgDepth)
+	}
+
+	fmt.Printf("\n%-14s  %5s\n", "week of", "done")
+	for _, w := range stats.Weeks {
+		fmt.Printf("%-14s  %5d\n", w.Start.Format("Mon 2006-01-02"), w.Completions)
+	}
+
+	fmt.Printf("\n%-20s  %5s  %s\n", "list", "done", "avg time to archive")
+	for _, l := range stats.Lists {
+		name := l.ListName
+		if name == "" {
+			name = "(unknown)"
+		}
+		fmt.Printf("%-20s  %5d  %s\n", name, l.Completions, formatStatsDuration(l.AvgTimeToArchive))
+	}
+}
+
+// formatStatsDuration formats d, a mean time from push to archive, to the minute. Zero means the
+// mean isn't known.
+func formatStatsDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return d.Round(time.Minute).String()
+}
+
+// describeError returns a description of err for the user. If err concerns a particular line of a
+// list, the line is shown too, with a caret pointing at the problem.
+func describeError(err error) string {
+	var indentErr *server.IndentError
+	if !errors.As(err, &indentErr) {
+		return err.Error()
+	}
+
+	// Show each indent level as 4 spaces, whatever the list's indent unit, so the caret lines up.
+	const tab = "    "
+	return fmt.Sprintf(
+		"%s\n\n%s%s%s\n%s%s^",
+		err.Error(),
+		tab,
+		strings.Repeat(tab, indentErr.Column-1),
+		strings.TrimLeft(indentErr.Text, " \t"),
+		tab,
+		strings.Repeat(tab, indentErr.Column-1),
+	)
 }
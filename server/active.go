@@ -0,0 +1,71 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// activeName is the name of the Datastore file in which the name of the active list is stored.
+const activeName = "active"
+
+// GetActiveList returns the name of the active list: the one that commands act on when they're not
+// told which list to use. If no list has been made active, the empty string is returned.
+func (ts *BasicTaskstore) GetActiveList() (string, error) {
+	b, err := ts.datastore.Get(activeName)
+	if err != nil {
+		// No active list yet
+		return "", nil
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// SetActiveList makes the list identified by name the active list. The list must exist.
+func (ts *BasicTaskstore) SetActiveList(name string) error {
+	if !ts.isListName(name) {
+		return fmt.Errorf("`%s` is not a task list", name)
+	}
+	if _, err := ts.readList(name); err != nil {
+		return err
+	}
+	return ts.datastore.Put(activeName, []byte(name+"\n"))
+}
+
+// Stack summarizes a task list for the stack of stacks (see Stacks).
+type Stack struct {
+	ListName string
+	// Active is set if this is the active list.
+	Active bool
+	// Top is the path (see TimeInterval) to the list's top frame, as determined by common.Top. It's
+	// empty if the list is empty or everything in it is blocked.
+	Top []string
+	// Empty is set if the list has no tasks.
+	Empty bool
+	// Error says why the list couldn't be read, if it couldn't.
+	Error string
+}
+
+// Stacks returns a summary of each task list, in lexical order by name.
+func (ts *BasicTaskstore) Stacks() ([]Stack, error) {
+	names, err := ts.ListNames()
+	if err != nil {
+		return nil, err
+	}
+	active, err := ts.GetActiveList()
+	if err != nil {
+		return nil, err
+	}
+
+	rslt := make([]Stack, 0, len(names))
+	for _, name := range names {
+		s := Stack{ListName: name, Active: name == active, Top: []string{}}
+		taskList, err := ts.GetList(name)
+		if err != nil {
+			s.Error = err.Error()
+		} else {
+			s.Empty = len(taskList) == 0
+			s.Top = topPath(taskList)
+		}
+		rslt = append(rslt, s)
+	}
+	return rslt, nil
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicTaskstore_ActiveList(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ts, cleanup := NewBasicTaskstoreWithTestdata()
+	defer cleanup()
+
+	name, err := ts.GetActiveList()
+	assert.Nil(err)
+	assert.Equal("", name)
+
+	err = ts.SetActiveList("make_pasta")
+	assert.Nil(err)
+	name, err = ts.GetActiveList()
+	assert.Nil(err)
+	assert.Equal("make_pasta", name)
+
+	// The active list must exist, and the pointer isn't a list itself
+	assert.IsType(&NoSuchListError{}, ts.SetActiveList("nonexistent"))
+	assert.NotNil(ts.SetActiveList("history"))
+	names, err := ts.ListNames()
+	assert.Nil(err)
+	assert.NotContains(names, "active")
+}
+
+func TestBasicTaskstore_Stacks(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ts, cleanup := NewBasicTaskstoreWithTestdata()
+	defer cleanup()
+
+	err := ts.SetActiveList("multiple_nested")
+	assert.Nil(err)
+	stacks, err := ts.Stacks()
+	assert.Nil(err)
+
+	byName := make(map[string]Stack)
+	for _, s := range stacks {
+		byName[s.ListName] = s
+	}
+	assert.Equal(5, len(stacks))
+	assert.Equal(Stack{ListName: "empty", Top: []string{}, Empty: true}, byName["empty"])
+	assert.Equal(
+		Stack{ListName: "make_pasta", Top: []string{"make pasta", "boil water", "put water in pot"}},
+		byName["make_pasta"],
+	)
+	assert.Equal(
+		Stack{ListName: "multiple_nested", Active: true, Top: []string{"task 0", "subtask 0", "subsubtask 0"}},
+		byName["multiple_nested"],
+	)
+	assert.NotEqual("", byName["malformed/excess_delta_indent"].Error)
+}
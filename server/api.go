@@ -1,13 +1,21 @@
 package server
 
 import (
+	"fmt"
 	"net"
 	"net/rpc"
 	"net/rpc/jsonrpc"
+	"sync"
 )
 
 type Server struct {
 	taskstore Taskstore
+
+	listener *net.TCPListener
+	// stop is closed by Stop to tell the server's background goroutines to exit.
+	stop chan struct{}
+	// stopOnce ensures that stop is only closed once, however many times Stop is called.
+	stopOnce sync.Once
 }
 
 // assignTaskstore obtains a default Taskstore implementation if one is not already injected.
@@ -25,7 +33,12 @@ func (api *Server) assignTaskstore() {
 // Start starts the Impulse API server, which will listen for requests until Stop is called.
 func (api *Server) Start(addr string) error {
 	api.assignTaskstore()
-	rpc.Register(api)
+	// Each Server gets its own rpc.Server, so that several Servers in the same process (e.g. in
+	// tests) each dispatch to their own Taskstore.
+	srv := rpc.NewServer()
+	if err := srv.Register(api); err != nil {
+		return err
+	}
 
 	tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
 	if err != nil {
@@ -36,20 +49,44 @@ func (api *Server) Start(addr string) error {
 	if err != nil {
 		return err
 	}
+	stop := make(chan struct{})
+	api.listener = listener
+	api.stop = stop
 
 	go func() {
 		for {
 			conn, err := listener.Accept()
 			if err != nil {
-				continue
+				select {
+				case <-stop:
+					return
+				default:
+					continue
+				}
 			}
-			jsonrpc.ServeConn(conn)
+			go srv.ServeCodec(jsonrpc.NewServerCodec(conn))
 		}
 	}()
 
+	go api.taskstore.Watch(stop)
+	go api.taskstore.Schedule(stop)
+
 	return nil
 }
 
+// Stop stops the Impulse API server.
+func (api *Server) Stop() error {
+	if api.stop == nil {
+		return fmt.Errorf("server not started")
+	}
+	var err error
+	api.stopOnce.Do(func() {
+		close(api.stop)
+		err = api.listener.Close()
+	})
+	return err
+}
+
 func NewServer(ts Taskstore) *Server {
 	return &Server{
 		taskstore: ts,
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// changeFeedSize is the number of events a ChangeFeed retains for subscribers who have fallen
+// behind.
+const changeFeedSize = 1000
+
+// ChangeEvent describes a single mutation of a task list.
+type ChangeEvent struct {
+	// Seq is the position of the event in the feed. The first event published has Seq 1, and Seq
+	// increases by one with each event published after that.
+	Seq      uint64
+	ListName string
+	// Op is the name of the operation that changed the list, e.g. "InsertTask".
+	Op string
+	// LineID identifies the node affected by the operation. It's empty if the operation affected
+	// the list as a whole (as with PutList).
+	LineID common.LineID
+	// StateID identifies the state of the list after the operation.
+	StateID string
+	// Error is set if the change left the list in a state that can't be parsed. This can only
+	// happen when the list is edited by another program.
+	Error string
+	Time  time.Time
+}
+
+// ChangeFeed distributes ChangeEvents to subscribers.
+//
+// Subscribers don't register with the ChangeFeed. Instead, they ask for all the events after the
+// last one they've seen (see Since). The ChangeFeed retains the most recent changeFeedSize events,
+// so a subscriber that is slow to come back for more (e.g. a long-polling API client between
+// requests) doesn't miss anything.
+type ChangeFeed struct {
+	events []ChangeEvent
+	seq    uint64
+	// published is closed (and replaced) whenever an event is published, waking up any goroutines
+	// blocked in Since.
+	published chan struct{}
+
+	mu sync.Mutex
+}
+
+// Publish adds ev to the feed.
+//
+// ev.Seq and, if it's not already set, ev.Time are filled in by Publish. The resulting event is
+// returned.
+func (feed *ChangeFeed) Publish(ev ChangeEvent) ChangeEvent {
+	feed.mu.Lock()
+	defer feed.mu.Unlock()
+
+	feed.seq++
+	ev.Seq = feed.seq
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	feed.events = append(feed.events, ev)
+	if len(feed.events) > changeFeedSize {
+		feed.events = feed.events[len(feed.events)-changeFeedSize:]
+	}
+
+	close(feed.published)
+	feed.published = make(chan struct{})
+	return ev
+}
+
+// Seq returns the Seq of the most recently published event, or 0 if no events have been published.
+func (feed *ChangeFeed) Seq() uint64 {
+	feed.mu.Lock()
+	defer feed.mu.Unlock()
+	return feed.seq
+}
+
+// Changed returns a channel that will be closed the next time an event is published.
+func (feed *ChangeFeed) Changed() <-chan struct{} {
+	feed.mu.Lock()
+	defer feed.mu.Unlock()
+	return feed.published
+}
+
+// after returns the retained events with Seq greater than seq that concern the list identified by
+// listName. If listName is empty, events for all lists are returned.
+//
+// The caller must hold feed.mu.
+func (feed *ChangeFeed) after(listName string, seq uint64) []ChangeEvent {
+	rslt := make([]ChangeEvent, 0)
+	for _, ev := range feed.events {
+		if ev.Seq <= seq {
+			continue
+		}
+		if listName != "" && ev.ListName != listName {
+			continue
+		}
+		rslt = append(rslt, ev)
+	}
+	return rslt
+}
+
+// Since returns the events with Seq greater than seq that concern the list identified by listName.
+// If listName is empty, events for all lists are returned.
+//
+// If there are no such events yet, Since blocks until one is published or until timeout has
+// elapsed, whichever comes first. On timeout, the returned slice is empty.
+func (feed *ChangeFeed) Since(listName string, seq uint64, timeout time.Duration) []ChangeEvent {
+	deadline := time.After(timeout)
+	for {
+		feed.mu.Lock()
+		rslt := feed.after(listName, seq)
+		published := feed.published
+		feed.mu.Unlock()
+
+		if len(rslt) > 0 {
+			return rslt
+		}
+
+		select {
+		case <-published:
+		case <-deadline:
+			return rslt
+		}
+	}
+}
+
+// NewChangeFeed returns an empty ChangeFeed.
+func NewChangeFeed() *ChangeFeed {
+	return &ChangeFeed{
+		events:    make([]ChangeEvent, 0),
+		published: make(chan struct{}),
+	}
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeFeed_Publish(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	feed := NewChangeFeed()
+	assert.Equal(uint64(0), feed.Seq())
+
+	ev := feed.Publish(ChangeEvent{ListName: "foo", Op: "PutList"})
+	assert.Equal(uint64(1), ev.Seq)
+	assert.False(ev.Time.IsZero())
+
+	ev = feed.Publish(ChangeEvent{ListName: "bar", Op: "PutList"})
+	assert.Equal(uint64(2), ev.Seq)
+	assert.Equal(uint64(2), feed.Seq())
+}
+
+func TestChangeFeed_Since(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	feed := NewChangeFeed()
+	feed.Publish(ChangeEvent{ListName: "foo", Op: "PutList"})
+	feed.Publish(ChangeEvent{ListName: "bar", Op: "InsertTask"})
+	feed.Publish(ChangeEvent{ListName: "foo", Op: "ArchiveLine"})
+
+	// all lists
+	rslt := feed.Since("", 0, time.Second)
+	assert.Equal(3, len(rslt))
+
+	// one list
+	rslt = feed.Since("foo", 0, time.Second)
+	assert.Equal(2, len(rslt))
+	assert.Equal("PutList", rslt[0].Op)
+	assert.Equal("ArchiveLine", rslt[1].Op)
+
+	// only events after the given seq
+	rslt = feed.Since("foo", 1, time.Second)
+	assert.Equal(1, len(rslt))
+	assert.Equal(uint64(3), rslt[0].Seq)
+}
+
+// Tests that Since blocks until an event is published.
+func TestChangeFeed_Since_Blocks(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	feed := NewChangeFeed()
+	feed.Publish(ChangeEvent{ListName: "foo", Op: "PutList"})
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		feed.Publish(ChangeEvent{ListName: "bar", Op: "PutList"})
+		feed.Publish(ChangeEvent{ListName: "foo", Op: "InsertTask"})
+	}()
+
+	rslt := feed.Since("foo", 1, 10*time.Second)
+	assert.Equal(1, len(rslt))
+	assert.Equal("InsertTask", rslt[0].Op)
+}
+
+// Tests that Since returns an empty slice on timeout.
+func TestChangeFeed_Since_Timeout(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	feed := NewChangeFeed()
+	feed.Publish(ChangeEvent{ListName: "foo", Op: "PutList"})
+	rslt := feed.Since("foo", 1, 10*time.Millisecond)
+	assert.Equal(0, len(rslt))
+}
@@ -1,13 +1,24 @@
 package server
 
 import (
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 )
 
 const DataDir = "/Users/danslimmon/j_workspace/impulse"
 
+// ErrStaleWrite is returned when a write is refused because the data it was based on has since
+// been changed by someone else.
+var ErrStaleWrite = errors.New("data has changed since it was read")
+
+// defaultPollInterval is how often a FilesystemDatastore checks its files for changes made by other
+// programs.
+const defaultPollInterval = time.Second
+
 // Datastore is an interface to raw marshaled Impulse data.
 //
 // A Datastore implementation is responsible for:
@@ -19,6 +30,11 @@ type Datastore interface {
 	Get(string) ([]byte, error)
 	// Put writes the given data to the file with the given name.
 	Put(string, []byte) error
+	// Create creates an empty file with the given name. If the file already exists, Create returns
+	// an error for which os.IsExist is true.
+	Create(string) error
+	// Remove removes the file with the given name.
+	Remove(string) error
 	// Append appends to the file identified by the given name, the given bytes.
 	//
 	// If no file with the given name exists, Append creates the file and sets its contents to the
@@ -26,6 +42,16 @@ type Datastore interface {
 	//
 	// The caller is responsible for including a \n.
 	Append(string, []byte) error
+	// List returns the names of all the files in the Datastore, in lexical order.
+	List() ([]string, error)
+	// CompareAndPut writes the given data to the file with the given name, but only if the file's
+	// current contents have the given StateID. Otherwise it returns ErrStaleWrite.
+	CompareAndPut(string, string, []byte) error
+	// Watch watches for changes made to the Datastore by other programs (e.g. a text editor), until
+	// the given channel is closed. The name of each file so changed is sent on the returned channel.
+	//
+	// Changes made through the Datastore's own methods are not reported.
+	Watch(<-chan struct{}) <-chan string
 }
 
 // FilesystemDatastore is a Datastore implementation in which trees are marshaled into files in a
@@ -38,6 +64,21 @@ type Datastore interface {
 // FilesystemDatastore.
 type FilesystemDatastore struct {
 	rootDir string
+
+	// pollInterval is how often Watch checks for changes.
+	pollInterval time.Duration
+	// known holds the last modification time and size we've seen for each file. We update it
+	// whenever we write a file, so that Watch can tell our own changes apart from those of other
+	// programs.
+	known map[string]fileState
+
+	mu sync.Mutex
+}
+
+// fileState is what FilesystemDatastore remembers about a file in order to detect changes to it.
+type fileState struct {
+	modTime time.Time
+	size    int64
 }
 
 // absPath returns the full path to the file that should contain the given task list's marshaled data.
@@ -50,13 +91,53 @@ func (ds *FilesystemDatastore) Get(name string) ([]byte, error) {
 	return ioutil.ReadFile(ds.absPath(name))
 }
 
+// remember records the current state of the file with the given name in ds.known.
+//
+// The caller must hold ds.mu.
+func (ds *FilesystemDatastore) remember(name string) {
+	fi, err := os.Stat(ds.absPath(name))
+	if err != nil {
+		delete(ds.known, name)
+		return
+	}
+	ds.known[name] = fileState{modTime: fi.ModTime(), size: fi.Size()}
+}
+
 // See Datastore interface
 func (ds *FilesystemDatastore) Put(name string, b []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	defer ds.remember(name)
 	return ioutil.WriteFile(ds.absPath(name), b, 0644)
 }
 
+// See Datastore interface
+func (ds *FilesystemDatastore) Create(name string) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	defer ds.remember(name)
+
+	f, err := os.OpenFile(ds.absPath(name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// See Datastore interface
+func (ds *FilesystemDatastore) Remove(name string) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	defer ds.remember(name)
+	return os.Remove(ds.absPath(name))
+}
+
 // See Datastore interface
 func (ds *FilesystemDatastore) Append(name string, b []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	defer ds.remember(name)
+
 	// If the file doesn't exist, create it, or append to the file
 	f, err := os.OpenFile(ds.absPath(name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
@@ -72,6 +153,123 @@ func (ds *FilesystemDatastore) Append(name string, b []byte) error {
 	return nil
 }
 
+// See Datastore interface
+func (ds *FilesystemDatastore) List() ([]string, error) {
+	names := make([]string, 0)
+	err := filepath.Walk(ds.rootDir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(ds.rootDir, p)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// See Datastore interface
+func (ds *FilesystemDatastore) CompareAndPut(name, stateId string, b []byte) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	cur, err := ioutil.ReadFile(ds.absPath(name))
+	if err != nil {
+		return err
+	}
+	if StateID(cur) != stateId {
+		return ErrStaleWrite
+	}
+
+	defer ds.remember(name)
+	return ioutil.WriteFile(ds.absPath(name), b, 0644)
+}
+
+// scan checks every file under ds.rootDir against ds.known, and returns the names of those that
+// have been created, modified, or removed since we last saw them.
+//
+// The caller must hold ds.mu.
+func (ds *FilesystemDatastore) scan() []string {
+	changed := make([]string, 0)
+	seen := make(map[string]bool)
+	filepath.Walk(ds.rootDir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(ds.rootDir, p)
+		if err != nil {
+			return nil
+		}
+		name := filepath.ToSlash(rel)
+		seen[name] = true
+
+		st := fileState{modTime: fi.ModTime(), size: fi.Size()}
+		if prev, ok := ds.known[name]; !ok || !prev.modTime.Equal(st.modTime) || prev.size != st.size {
+			ds.known[name] = st
+			changed = append(changed, name)
+		}
+		return nil
+	})
+
+	for name := range ds.known {
+		if !seen[name] {
+			delete(ds.known, name)
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+// See Datastore interface
+//
+// FilesystemDatastore detects changes by polling the modification time and size of each file
+// under rootDir.
+func (ds *FilesystemDatastore) Watch(stop <-chan struct{}) <-chan string {
+	// Take stock of the files as they are now, so that we only report subsequent changes.
+	ds.mu.Lock()
+	ds.scan()
+	ds.mu.Unlock()
+
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(ds.pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			ds.mu.Lock()
+			changed := ds.scan()
+			ds.mu.Unlock()
+
+			for _, name := range changed {
+				select {
+				case ch <- name:
+				case <-stop:
+					return
+				}
+			}
+		}
+	}()
+	return ch
+}
+
 func NewFilesystemDatastore(rootDir string) *FilesystemDatastore {
-	return &FilesystemDatastore{rootDir: rootDir}
+	return &FilesystemDatastore{
+		rootDir:      rootDir,
+		pollInterval: defaultPollInterval,
+		known:        make(map[string]fileState),
+	}
 }
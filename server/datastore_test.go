@@ -2,7 +2,11 @@ package server
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -28,3 +32,126 @@ func TestFilesystemDatastore_Append(t *testing.T) {
 	assert.Nil(err)
 	assert.Equal([]byte("first line\nsecond line\n"), rslt, fmt.Sprintf("unexpected file contents: '%s'", string(rslt)))
 }
+
+func TestFilesystemDatastore_Create(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+
+	err := ds.Create("foo")
+	assert.Nil(err)
+	rslt, err := ds.Get("foo")
+	assert.Nil(err)
+	assert.Equal(0, len(rslt))
+
+	// Files that already exist are left alone
+	err = ds.Create("make_pasta")
+	assert.True(os.IsExist(err))
+	rslt, err = ds.Get("make_pasta")
+	assert.Nil(err)
+	assert.NotEqual(0, len(rslt))
+}
+
+func TestFilesystemDatastore_Remove(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+
+	err := ds.Remove("make_pasta")
+	assert.Nil(err)
+	_, err = ds.Get("make_pasta")
+	assert.True(os.IsNotExist(err))
+
+	err = ds.Remove("make_pasta")
+	assert.True(os.IsNotExist(err))
+}
+
+func TestFilesystemDatastore_CompareAndPut(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+
+	b, err := ds.Get("make_pasta")
+	assert.Nil(err)
+
+	// Base state is current
+	err = ds.CompareAndPut("make_pasta", StateID(b), []byte("first\n"))
+	assert.Nil(err)
+	rslt, err := ds.Get("make_pasta")
+	assert.Nil(err)
+	assert.Equal([]byte("first\n"), rslt)
+
+	// Base state is stale
+	err = ds.CompareAndPut("make_pasta", StateID(b), []byte("second\n"))
+	assert.Equal(ErrStaleWrite, err)
+	rslt, err = ds.Get("make_pasta")
+	assert.Nil(err)
+	assert.Equal([]byte("first\n"), rslt)
+}
+
+func TestFilesystemDatastore_Watch(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ds.pollInterval = 10 * time.Millisecond
+
+	stop := make(chan struct{})
+	defer close(stop)
+	ch := ds.Watch(stop)
+
+	// Changes made through the datastore are not reported
+	err := ds.Put("make_pasta", []byte("put through the datastore\n"))
+	assert.Nil(err)
+	err = ds.Append("history", []byte("appended through the datastore\n"))
+	assert.Nil(err)
+
+	// Changes made by somebody else are
+	err = ioutil.WriteFile(filepath.Join(ds.rootDir, "multiple_nested"), []byte("edited\n"), 0644)
+	assert.Nil(err)
+
+	select {
+	case name := <-ch:
+		assert.Equal("multiple_nested", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for change notification")
+	}
+
+	// Removals are reported too
+	err = os.Remove(filepath.Join(ds.rootDir, "make_pasta"))
+	assert.Nil(err)
+	select {
+	case name := <-ch:
+		assert.Equal("make_pasta", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for change notification")
+	}
+}
+
+func TestFilesystemDatastore_List(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+
+	names, err := ds.List()
+	assert.Nil(err)
+	assert.Equal(
+		[]string{
+			"empty",
+			"make_pasta",
+			"malformed/excess_delta_indent",
+			"malformed/no_trailing_newline",
+			"multiple_nested",
+		},
+		names,
+	)
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/rpc"
+)
+
+// ErrNoTrailingNewline means a list's data doesn't end in a newline.
+var ErrNoTrailingNewline = errors.New("data does not end in newline")
+
+// NoSuchListError is returned when there's no list with the given name. This is distinct from the
+// list being empty, which is perfectly valid.
+type NoSuchListError struct {
+	ListName string
+}
+
+func (e *NoSuchListError) Error() string {
+	return fmt.Sprintf("no such list `%s`", e.ListName)
+}
+
+// ParseError is returned when a list's data can't be parsed for a reason that concerns the data as
+// a whole. Err is ErrNoTrailingNewline, so callers can check with errors.Is.
+type ParseError struct {
+	ListName string
+	Err      error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("data for list `%s`: %s", e.ListName, e.Err.Error())
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// IndentError is returned when a line in a list's data is indented more than one level deeper than
+// the line below it allows.
+type IndentError struct {
+	ListName string
+	// Line is the number of the offending line, counting from 1 at the top of the file.
+	Line int
+	// Column is the column (counting from 1, with each indent unit counting as one column) at
+	// which the offending line's text begins.
+	Column int
+	// Text is the offending line, indentation included.
+	Text string
+	// MaxIndent is the deepest the line may be indented, given the line below it.
+	MaxIndent int
+}
+
+func (e *IndentError) Error() string {
+	return fmt.Sprintf(
+		"list `%s`, line %d, column %d: line is indented %d levels, but may be indented at most %d",
+		e.ListName,
+		e.Line,
+		e.Column,
+		e.Column-1,
+		e.MaxIndent,
+	)
+}
+
+// Error codes identify the typed errors that can be returned by the API (see apiError).
+const (
+	CodeNoSuchList        = "no_such_list"
+	CodeNoTrailingNewline = "no_trailing_newline"
+	CodeIndent            = "indent"
+)
+
+// wireError is the form in which a typed error crosses the API.
+//
+// net/rpc only passes along the text of an error. So, a typed error is sent as the JSON encoding
+// of a wireError, and DecodeError turns it back into the original type on the other end.
+type wireError struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	ListName string `json:"list_name"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Text     string `json:"text,omitempty"`
+	// MaxIndent is only meaningful for CodeIndent, so it's not omitted when zero.
+	MaxIndent int `json:"max_indent"`
+}
+
+func (e *wireError) Error() string {
+	b, _ := json.Marshal(e)
+	return string(b)
+}
+
+// apiError prepares err to be returned by an API method.
+//
+// Typed errors are converted to wireErrors, so that clients can recover their types with
+// DecodeError. Other errors are returned as they are.
+func apiError(err error) error {
+	var noSuchListErr *NoSuchListError
+	var parseErr *ParseError
+	var indentErr *IndentError
+	switch {
+	case errors.As(err, &noSuchListErr):
+		return &wireError{Code: CodeNoSuchList, Message: noSuchListErr.Error(), ListName: noSuchListErr.ListName}
+	case errors.As(err, &indentErr):
+		return &wireError{
+			Code:      CodeIndent,
+			Message:   indentErr.Error(),
+			ListName:  indentErr.ListName,
+			Line:      indentErr.Line,
+			Column:    indentErr.Column,
+			Text:      indentErr.Text,
+			MaxIndent: indentErr.MaxIndent,
+		}
+	case errors.As(err, &parseErr) && errors.Is(err, ErrNoTrailingNewline):
+		return &wireError{Code: CodeNoTrailingNewline, Message: parseErr.Error(), ListName: parseErr.ListName}
+	}
+	return err
+}
+
+// DecodeError recovers the typed error, if any, from err, an error returned by an API call.
+//
+// Errors that weren't typed on the server side are returned as they are.
+func DecodeError(err error) error {
+	serverErr, ok := err.(rpc.ServerError)
+	if !ok {
+		return err
+	}
+	var we wireError
+	if json.Unmarshal([]byte(serverErr), &we) != nil {
+		return err
+	}
+
+	switch we.Code {
+	case CodeIndent:
+		return &IndentError{
+			ListName:  we.ListName,
+			Line:      we.Line,
+			Column:    we.Column,
+			Text:      we.Text,
+			MaxIndent: we.MaxIndent,
+		}
+	case CodeNoSuchList:
+		return &NoSuchListError{ListName: we.ListName}
+	case CodeNoTrailingNewline:
+		return &ParseError{ListName: we.ListName, Err: ErrNoTrailingNewline}
+	}
+	return err
+}
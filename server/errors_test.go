@@ -0,0 +1,88 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/rpc"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicTaskstore_GetList_ErrorTypes(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	_, err := ts.GetList("malformed/missing")
+	var noSuchListErr *NoSuchListError
+	if assert.True(errors.As(err, &noSuchListErr)) {
+		assert.Equal("malformed/missing", noSuchListErr.ListName)
+	}
+
+	_, err = ts.GetList("malformed/no_trailing_newline")
+	assert.True(errors.Is(err, ErrNoTrailingNewline))
+
+	// A single line without a newline isn't an empty list.
+	err = ds.Put("one_line", []byte("foo"))
+	assert.Nil(err)
+	_, err = ts.GetList("one_line")
+	assert.True(errors.Is(err, ErrNoTrailingNewline))
+
+	_, err = ts.GetList("malformed/excess_delta_indent")
+	var indentErr *IndentError
+	if assert.True(errors.As(err, &indentErr)) {
+		assert.Equal(&IndentError{
+			ListName:  "malformed/excess_delta_indent",
+			Line:      1,
+			Column:    4,
+			Text:      "\t\t\tthis one is double-indented oh no!",
+			MaxIndent: 2,
+		}, indentErr)
+	}
+
+	err = ds.Put("bottom_indented", []byte("\tboil water\n\tmake pasta\n"))
+	assert.Nil(err)
+	_, err = ts.GetList("bottom_indented")
+	assert.True(errors.As(err, &indentErr))
+	assert.Equal(2, indentErr.Line)
+	assert.Equal(0, indentErr.MaxIndent)
+}
+
+// Tests that typed errors survive the trip through the API.
+func TestDecodeError(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	indentErr := &IndentError{ListName: "foo", Line: 3, Column: 5, Text: "\t\t\t\tbar", MaxIndent: 1}
+	errs := []error{
+		&NoSuchListError{ListName: "foo"},
+		&ParseError{ListName: "foo", Err: ErrNoTrailingNewline},
+		indentErr,
+		fmt.Errorf("while inserting: %w", indentErr),
+	}
+	for _, err := range errs {
+		// This is what net/rpc does to errors
+		got := DecodeError(rpc.ServerError(apiError(err).Error()))
+		// Any wrapping is lost, but the typed error itself is intact.
+		assert.True(strings.HasSuffix(err.Error(), got.Error()))
+		assert.Equal(errors.As(err, new(*NoSuchListError)), errors.As(got, new(*NoSuchListError)))
+		assert.Equal(errors.Is(err, ErrNoTrailingNewline), errors.Is(got, ErrNoTrailingNewline))
+	}
+
+	var gotIndentErr *IndentError
+	got := DecodeError(rpc.ServerError(apiError(errs[3]).Error()))
+	if assert.True(errors.As(got, &gotIndentErr)) {
+		assert.Equal(indentErr, gotIndentErr)
+	}
+
+	// Untyped errors are left alone
+	err := errors.New("no such task")
+	assert.Equal(err, apiError(err))
+	assert.Equal(rpc.ServerError("no such task"), DecodeError(rpc.ServerError("no such task")))
+	assert.Nil(apiError(nil))
+}
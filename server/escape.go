@@ -0,0 +1,97 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// escapeReferent returns referent escaped for use as the text of a line of basic-format data.
+//
+// Characters that would otherwise change the structure of the data are replaced by escape
+// sequences beginning with a backslash:
+//
+//	\\   a backslash
+//	\t   a tab
+//	\n   a newline
+//	\r   a carriage return
+//	\    (a backslash followed by a space) a space at the beginning of the referent, which would
+//	     otherwise be taken for indentation
+//	\#   a `#` at the beginning of the referent, if it would otherwise make the line a comment
+//	\|   a `|` at the beginning of the referent, if it would otherwise make the line part of a note
+//
+// unescapeReferent reverses the escaping.
+func escapeReferent(referent string) string {
+	var b strings.Builder
+	for i, c := range referent {
+		switch {
+		case c == '\\':
+			b.WriteString(`\\`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == ' ' && i == 0:
+			b.WriteString(`\ `)
+		case c == '#' && i == 0 && isCommentLine(referent):
+			b.WriteString(`\#`)
+		case c == '|' && i == 0 && isNoteLine(referent):
+			b.WriteString(`\|`)
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// validateTask returns an error if task can't be represented in the basic format.
+//
+// Any character can be escaped, but a node whose referent is empty would be written as a blank
+// line, which isn't a node at all.
+func validateTask(task *common.Task) error {
+	return task.RootNode.Walk(func(n *common.TreeNode) error {
+		if n.Referent == "" {
+			return fmt.Errorf("task has a node with an empty referent")
+		}
+		return nil
+	})
+}
+
+// unescapeReferent returns the referent represented by text, the text of a line of basic-format
+// data (see escapeReferent).
+//
+// A backslash that doesn't begin an escape sequence is left as it is, so that text written by hand
+// needn't escape every backslash.
+func unescapeReferent(text string) string {
+	if !strings.Contains(text, `\`) {
+		return text
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i+1 == len(text) {
+			b.WriteByte(text[i])
+			continue
+		}
+		switch text[i+1] {
+		case '\\':
+			b.WriteByte('\\')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case ' ', '#', '|':
+			b.WriteByte(text[i+1])
+		default:
+			b.WriteByte(text[i])
+			continue
+		}
+		i++
+	}
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_escapeReferent(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	type testCase struct {
+		Referent string
+		Exp      string
+	}
+
+	testCases := []testCase{
+		testCase{Referent: "boil water", Exp: "boil water"},
+		testCase{Referent: "\tboil water", Exp: `\tboil water`},
+		testCase{Referent: "boil\nwater", Exp: `boil\nwater`},
+		testCase{Referent: "boil\r\nwater", Exp: `boil\r\nwater`},
+		testCase{Referent: `C:\pasta`, Exp: `C:\\pasta`},
+		testCase{Referent: "  boil water", Exp: `\  boil water`},
+		testCase{Referent: "# boil water", Exp: `\# boil water`},
+		testCase{Referent: "#", Exp: `\#`},
+		testCase{Referent: "| boil water", Exp: `\| boil water`},
+		// tags aren't comments
+		testCase{Referent: "#kitchen boil water", Exp: "#kitchen boil water"},
+		testCase{Referent: "boil water #", Exp: "boil water #"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(tc.Exp, escapeReferent(tc.Referent), tc.Referent)
+		assert.Equal(tc.Referent, unescapeReferent(escapeReferent(tc.Referent)), tc.Referent)
+	}
+}
+
+func Test_unescapeReferent(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	// Backslashes that don't begin escape sequences are left alone
+	assert.Equal(`C:\pasta`, unescapeReferent(`C:\pasta`))
+	assert.Equal(`boil water\`, unescapeReferent(`boil water\`))
+	assert.Equal("boil\twater\\", unescapeReferent(`boil\twater\\`))
+}
@@ -0,0 +1,246 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// focusName is the name of the Datastore file in which the current focus session is stored.
+const focusName = "focus"
+
+// Focus session outcomes, as recorded in the history file.
+const (
+	// FocusCompleted means the frame was archived during the session.
+	FocusCompleted = "completed"
+	// FocusInterrupted means another frame was pushed on top of the frame during the session.
+	FocusInterrupted = "interrupted"
+	// FocusExpired means the session's time ran out with the frame still on top.
+	FocusExpired = "expired"
+	// FocusAbandoned means the session was stopped before its time ran out.
+	FocusAbandoned = "abandoned"
+)
+
+// FocusSession is a timed period of work on a list's top frame, à la the Pomodoro technique.
+type FocusSession struct {
+	ListName string
+	// Path is the path to the frame (see TimeInterval) that was on top when the session started.
+	Path     []string
+	Start    time.Time
+	Duration time.Duration
+}
+
+// End returns the time at which fs's time runs out.
+func (fs *FocusSession) End() time.Time {
+	return fs.Start.Add(fs.Duration)
+}
+
+// Remaining returns the amount of time left in fs as of now. It's never negative.
+func (fs *FocusSession) Remaining(now time.Time) time.Duration {
+	d := fs.End().Sub(now)
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// marshal returns the representation of fs stored in the focus file.
+//
+// It consists of tab-separated fields: start time in RFC 3339 format, duration, list name, and
+// then the escaped elements of the path (see escapePath).
+func (fs *FocusSession) marshal() []byte {
+	fields := append([]string{fs.Start.Format(time.RFC3339), fs.Duration.String(), fs.ListName}, escapePath(fs.Path)...)
+	return []byte(strings.Join(fields, "\t") + "\n")
+}
+
+// unmarshalFocusSession parses the contents of the focus file. If there's no session in progress,
+// it returns nil.
+func unmarshalFocusSession(b []byte) (*FocusSession, error) {
+	b = bytes.TrimRight(b, "\n")
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	fields := strings.Split(string(b), "\t")
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("malformed focus session `%s`", string(b))
+	}
+	start, err := time.Parse(time.RFC3339, fields[0])
+	if err != nil {
+		return nil, err
+	}
+	d, err := time.ParseDuration(fields[1])
+	if err != nil {
+		return nil, err
+	}
+	return &FocusSession{
+		ListName: fields[2],
+		Path:     unescapePath(fields[3:]),
+		Start:    start,
+		Duration: d,
+	}, nil
+}
+
+// focusHistoryLine returns a line for the history file recording that fs ended at t with the given
+// outcome.
+//
+// Focus history lines are of the form:
+//
+//	2021-12-30T19:24:48 [focus completed 25m0s] make pasta > boil water > put water in pot
+//
+// where the duration is how long the session actually lasted, and the elements of the path are
+// escaped (see escapePath).
+func focusHistoryLine(fs *FocusSession, outcome string, t time.Time) []byte {
+	elapsed := t.Sub(fs.Start).Round(time.Second)
+	return []byte(fmt.Sprintf(
+		"%s [focus %s %s] %s\n",
+		t.Format(historyTimeFormat),
+		outcome,
+		elapsed,
+		strings.Join(escapePath(fs.Path), " > "),
+	))
+}
+
+// findPath returns the node in taskList at the given path, or nil if there's no such node.
+func findPath(taskList []*common.Task, path []string) *common.TreeNode {
+	var found *common.TreeNode
+	for _, t := range taskList {
+		t.RootNode.Walk(func(n *common.TreeNode) error {
+			d := n.Depth()
+			if d >= len(path) || n.Referent != path[d] {
+				return common.SkipSubtree
+			}
+			if d == len(path)-1 {
+				found = n
+				return common.SkipSubtree
+			}
+			return nil
+		})
+		if found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// readFocus returns the focus session in progress, or nil if there is none.
+//
+// If the session's time has run out, it's ended with outcome FocusExpired, and nil is returned.
+//
+// The caller must hold ts.focusMu.
+func (ts *BasicTaskstore) readFocus() (*FocusSession, error) {
+	b, err := ts.datastore.Get(focusName)
+	if err != nil {
+		// No focus file yet
+		return nil, nil
+	}
+	fs, err := unmarshalFocusSession(b)
+	if err != nil || fs == nil {
+		return nil, err
+	}
+
+	if !time.Now().Before(fs.End()) {
+		return nil, ts.endFocus(fs, FocusExpired, fs.End())
+	}
+	return fs, nil
+}
+
+// endFocus ends fs at time t with the given outcome, recording the outcome in the history file.
+//
+// The caller must hold ts.focusMu.
+func (ts *BasicTaskstore) endFocus(fs *FocusSession, outcome string, t time.Time) error {
+	if err := ts.datastore.Put(focusName, []byte{}); err != nil {
+		return err
+	}
+	return ts.appendHistory(focusHistoryLine(fs, outcome, t))
+}
+
+// StartFocus starts a focus session of duration d on the current top frame of the list identified
+// by listName.
+//
+// Only one focus session can be in progress at a time.
+func (ts *BasicTaskstore) StartFocus(listName string, d time.Duration) (*FocusSession, error) {
+	if d <= 0 {
+		return nil, fmt.Errorf("focus session duration must be positive")
+	}
+
+	ts.focusMu.Lock()
+	defer ts.focusMu.Unlock()
+
+	if fs, err := ts.readFocus(); err != nil {
+		return nil, err
+	} else if fs != nil {
+		return nil, fmt.Errorf("a focus session is already in progress on list `%s`", fs.ListName)
+	}
+
+	taskList, err := ts.GetList(listName)
+	if err != nil {
+		return nil, err
+	}
+	path := topPath(taskList)
+	if len(path) == 0 {
+		return nil, fmt.Errorf("list `%s` has no top frame to focus on", listName)
+	}
+
+	fs := &FocusSession{
+		ListName: listName,
+		Path:     path,
+		Start:    time.Now().Truncate(time.Second),
+		Duration: d,
+	}
+	if err := ts.datastore.Put(focusName, fs.marshal()); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// GetFocus returns the focus session in progress, or nil if there is none.
+func (ts *BasicTaskstore) GetFocus() (*FocusSession, error) {
+	ts.focusMu.Lock()
+	defer ts.focusMu.Unlock()
+	return ts.readFocus()
+}
+
+// StopFocus ends the focus session in progress with outcome FocusAbandoned.
+//
+// If there's no session in progress, StopFocus returns an error.
+func (ts *BasicTaskstore) StopFocus() error {
+	ts.focusMu.Lock()
+	defer ts.focusMu.Unlock()
+
+	fs, err := ts.readFocus()
+	if err != nil {
+		return err
+	}
+	if fs == nil {
+		return fmt.Errorf("no focus session in progress")
+	}
+	return ts.endFocus(fs, FocusAbandoned, time.Now())
+}
+
+// checkFocus ends the focus session in progress, if its frame is no longer on top of taskList, the
+// current state of the list identified by listName.
+//
+// If the frame is gone, it was completed. If it's still in the list but not on top, it was
+// interrupted.
+func (ts *BasicTaskstore) checkFocus(listName string, taskList []*common.Task) error {
+	ts.focusMu.Lock()
+	defer ts.focusMu.Unlock()
+
+	fs, err := ts.readFocus()
+	if err != nil || fs == nil || fs.ListName != listName {
+		return err
+	}
+	if pathsEqual(topPath(taskList), fs.Path) {
+		return nil
+	}
+
+	outcome := FocusCompleted
+	if findPath(taskList, fs.Path) != nil {
+		outcome = FocusInterrupted
+	}
+	return ts.endFocus(fs, outcome, time.Now())
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests the outcomes with which focus sessions end.
+func TestBasicTaskstore_Focus_Outcomes(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	// Pushing a frame interrupts the session
+	_, err := ts.StartFocus("make_pasta", time.Hour)
+	assert.Nil(err)
+	err = ts.InsertTask(common.LineID("make_pasta:0"), common.NewTask(common.NewTreeNode("check Twitter")))
+	assert.Nil(err)
+	fs, err := ts.GetFocus()
+	assert.Nil(err)
+	assert.Nil(fs)
+
+	// Archiving the frame completes the session
+	_, err = ts.StartFocus("make_pasta", time.Hour)
+	assert.Nil(err)
+	err = ts.ArchiveLine(common.GetLineID("make_pasta", "check Twitter"))
+	assert.Nil(err)
+
+	// Changes to other lists, or that leave the frame on top, don't affect the session
+	_, err = ts.StartFocus("make_pasta", time.Hour)
+	assert.Nil(err)
+	err = ts.ArchiveLine(common.GetLineID("multiple_nested", "\tsubtask 1"))
+	assert.Nil(err)
+	err = ts.ArchiveLine(common.GetLineID("make_pasta", "\tdrain pasta"))
+	assert.Nil(err)
+	fs, err = ts.GetFocus()
+	assert.Nil(err)
+	assert.NotNil(fs)
+	err = ts.StopFocus()
+	assert.Nil(err)
+
+	// Sessions whose time has run out expire
+	_, err = ts.StartFocus("make_pasta", -time.Minute)
+	assert.NotNil(err)
+	fs = &FocusSession{
+		ListName: "make_pasta",
+		Path:     []string{"make pasta", "boil water", "put water in pot"},
+		Start:    time.Now().Add(-time.Hour),
+		Duration: 25 * time.Minute,
+	}
+	err = ds.Put(focusName, fs.marshal())
+	assert.Nil(err)
+	fs, err = ts.GetFocus()
+	assert.Nil(err)
+	assert.Nil(fs)
+
+	b, err := ds.Get("history")
+	assert.Nil(err)
+	ts0 := "[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}:[0-9]{2}"
+	assert.True(regexp.MustCompile(ts0 + ` \[focus interrupted [0-9]+s\] make pasta > boil water > put water in pot\n`).Match(b))
+	assert.True(regexp.MustCompile(ts0 + ` \[focus completed [0-9]+s\] check Twitter\n`).Match(b))
+	assert.True(regexp.MustCompile(ts0 + ` \[focus abandoned [0-9]+s\] make pasta > boil water > put water in pot\n`).Match(b))
+	assert.True(regexp.MustCompile(ts0 + ` \[focus expired 25m0s\] make pasta > boil water > put water in pot\n`).Match(b))
+}
+
+func TestFocusSession_marshal(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	fs := &FocusSession{
+		ListName: "make_pasta",
+		Path:     []string{"make pasta", "buy:\n- eggs\t(6)"},
+		Start:    time.Date(2021, 12, 30, 19, 0, 0, 0, time.UTC),
+		Duration: 25 * time.Minute,
+	}
+	got, err := unmarshalFocusSession(fs.marshal())
+	assert.Nil(err)
+	assert.Equal(fs, got)
+}
+
+func TestFocusSession_Remaining(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	t0 := time.Date(2021, 12, 30, 19, 0, 0, 0, time.UTC)
+	fs := &FocusSession{Start: t0, Duration: 25 * time.Minute}
+	assert.Equal(25*time.Minute, fs.Remaining(t0))
+	assert.Equal(5*time.Minute, fs.Remaining(t0.Add(20*time.Minute)))
+	assert.Equal(time.Duration(0), fs.Remaining(t0.Add(time.Hour)))
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Problem is something wrong with the basic-format data of a task list.
+type Problem struct {
+	// Line is the number of the offending line, counting from 1 at the top of the file. It's 0 if
+	// the problem concerns the file as a whole.
+	Line        int
+	Description string
+	// Repairable is set if RepairList can fix the problem.
+	Repairable bool
+}
+
+// CheckResult is the outcome of validating a task list (see CheckList).
+type CheckResult struct {
+	Problems []Problem
+	// StateID identifies the state of the list that was checked.
+	StateID string
+	// Diff shows the changes RepairList would make, as lines prefixed with "-" (removed), "+"
+	// (added), or " " (unchanged), followed, as in diff(1), by a line beginning with `\` if the
+	// file lacks a final newline. It's empty if there's nothing to repair.
+	Diff []string
+}
+
+// splitFileLines splits b into lines, without their newline characters. It also reports whether b
+// ends in a newline.
+func splitFileLines(b []byte) ([]string, bool) {
+	if len(b) == 0 {
+		return []string{}, true
+	}
+	s := string(b)
+	terminated := strings.HasSuffix(s, "\n")
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n"), terminated
+}
+
+// checkList returns all the problems with b, the basic-format data of a task list indented with
+// unit.
+//
+// Where unmarshalList stops at the first problem it encounters, checkList keeps going, so that
+// everything can be fixed in one pass.
+func checkList(b []byte, unit string) []Problem {
+	problems := make([]Problem, 0)
+	lines, terminated := splitFileLines(b)
+	// Indentation is checked from the bottom of the file up, since a line's indentation is only
+	// constrained by that of the line below it. But we want to report problems from the top down,
+	// so we collect them in reverse and flip them at the end.
+	indentProblems := make([]Problem, 0)
+	prevIndent := -1
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := lines[i]
+		if isCommentLine(line) || isNoteLine(line) {
+			continue
+		}
+
+		indent, _ := splitIndent(line, unit)
+		if indent > prevIndent+1 {
+			desc := fmt.Sprintf(
+				"line is indented %d levels, but the line below it only allows up to %d",
+				indent,
+				prevIndent+1,
+			)
+			if prevIndent == -1 {
+				desc = fmt.Sprintf("bottom line is indented %d levels, but must not be indented", indent)
+			}
+			indentProblems = append(indentProblems, Problem{
+				Line:        i + 1,
+				Description: desc,
+				Repairable:  true,
+			})
+			indent = prevIndent + 1
+		}
+		prevIndent = indent
+	}
+	for i := len(indentProblems) - 1; i >= 0; i-- {
+		problems = append(problems, indentProblems[i])
+	}
+
+	if !terminated {
+		problems = append(problems, Problem{
+			Line:        len(lines),
+			Description: "last line does not end in a newline",
+			Repairable:  true,
+		})
+	}
+	return problems
+}
+
+// repairList returns b, the basic-format data of a task list indented with unit, with the repairable
+// problems found by checkList fixed: over-indented lines are outdented as little as possible, and a
+// missing final newline is added.
+func repairList(b []byte, unit string) []byte {
+	lines, _ := splitFileLines(b)
+	prevIndent := -1
+	for i := len(lines) - 1; i >= 0; i-- {
+		if isCommentLine(lines[i]) || isNoteLine(lines[i]) {
+			continue
+		}
+		indent, text := splitIndent(lines[i], unit)
+		if indent > prevIndent+1 {
+			indent = prevIndent + 1
+			lines[i] = strings.Repeat(unit, indent) + text
+		}
+		prevIndent = indent
+	}
+
+	var rslt bytes.Buffer
+	for _, line := range lines {
+		rslt.WriteString(line + "\n")
+	}
+	return rslt.Bytes()
+}
+
+// lineDiff returns a line-by-line diff that turns a into b, in the format described in the
+// CheckResult docs.
+func lineDiff(a, b []string) []string {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	rslt := make([]string, 0)
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			rslt = append(rslt, " "+a[i])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			rslt = append(rslt, "-"+a[i])
+			i++
+		default:
+			rslt = append(rslt, "+"+b[j])
+			j++
+		}
+	}
+	return rslt
+}
+
+// CheckList validates the basic-format data of the list identified by name, reporting every
+// problem with it rather than just the first. It also works out how RepairList would fix the
+// problems that can be fixed.
+func (ts *BasicTaskstore) CheckList(name string) (*CheckResult, error) {
+	b, err := ts.readList(name)
+	if err != nil {
+		return nil, err
+	}
+
+	unit := detectIndent(b, ts.indent)
+	rslt := &CheckResult{
+		Problems: checkList(b, unit),
+		StateID:  StateID(b),
+		Diff:     []string{},
+	}
+	if repaired := repairList(b, unit); !bytes.Equal(repaired, b) {
+		before, _ := splitFileLines(b)
+		after, _ := splitFileLines(repaired)
+		rslt.Diff = lineDiff(before, after)
+		if !bytes.HasSuffix(b, []byte("\n")) {
+			rslt.Diff = append(rslt.Diff, `\ No newline at end of original file`)
+		}
+	}
+	return rslt, nil
+}
+
+// RepairList fixes the repairable problems (see Problem) in the list identified by name.
+//
+// stateId must be the StateID of the list as checked by CheckList, so that the repair made is the
+// one the caller was shown. If the list has changed since then, RepairList returns ErrStaleWrite.
+func (ts *BasicTaskstore) RepairList(name, stateId string) error {
+	b, err := ts.readList(name)
+	if err != nil {
+		return err
+	}
+	if StateID(b) != stateId {
+		return ErrStaleWrite
+	}
+
+	repaired := repairList(b, detectIndent(b, ts.indent))
+	if bytes.Equal(repaired, b) {
+		return nil
+	}
+	if err := ts.datastore.CompareAndPut(name, stateId, repaired); err != nil {
+		return err
+	}
+	ts.publish(name, "RepairList", "", repaired)
+	return nil
+}
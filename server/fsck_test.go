@@ -0,0 +1,165 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_checkList(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	type testCase struct {
+		In  string
+		Exp []Problem
+	}
+
+	testCases := []testCase{
+		testCase{
+			In:  "\tboil water\nmake pasta\n",
+			Exp: []Problem{},
+		},
+		testCase{
+			In:  "",
+			Exp: []Problem{},
+		},
+		testCase{
+			In: "\t\t\tthis one is double-indented oh no!\n\tthis one is okay\nmmhmm\n",
+			Exp: []Problem{
+				Problem{
+					Line:        1,
+					Description: "line is indented 3 levels, but the line below it only allows up to 2",
+					Repairable:  true,
+				},
+			},
+		},
+		testCase{
+			In: "\t\tput water in pot\n\n\tboil water\n  \n\tdrain pasta\nmake pasta",
+			Exp: []Problem{
+				Problem{Line: 6, Description: "last line does not end in a newline", Repairable: true},
+			},
+		},
+		testCase{
+			// comments may be indented however you like
+			In:  "\t\t\t# turn it up\n\t\tput pot on burner\n# boiling\n\tboil water\nmake pasta\n",
+			Exp: []Problem{},
+		},
+		testCase{
+			In: "\tboil water\n\t\tmake pasta\n",
+			Exp: []Problem{
+				Problem{
+					Line:        2,
+					Description: "bottom line is indented 2 levels, but must not be indented",
+					Repairable:  true,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(tc.Exp, checkList([]byte(tc.In), IndentTab), tc.In)
+	}
+}
+
+func Test_repairList(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	type testCase struct {
+		In  string
+		Exp string
+	}
+
+	testCases := []testCase{
+		testCase{
+			In:  "\tboil water\nmake pasta\n",
+			Exp: "\tboil water\nmake pasta\n",
+		},
+		testCase{
+			In:  "\t\t\tthis one is double-indented oh no!\n\tthis one is okay\nmmhmm\n",
+			Exp: "\t\tthis one is double-indented oh no!\n\tthis one is okay\nmmhmm\n",
+		},
+		testCase{
+			In:  "\t\tput water in pot\n\n\tboil water\n  \n\tdrain pasta\nmake pasta",
+			Exp: "\t\tput water in pot\n\n\tboil water\n  \n\tdrain pasta\nmake pasta\n",
+		},
+		testCase{
+			In:  "\t\t\tput water in pot\n\t\tboil water\n",
+			Exp: "\tput water in pot\nboil water\n",
+		},
+		testCase{
+			In:  "      put water in pot\n  boil water\nmake pasta\n",
+			Exp: "    put water in pot\n  boil water\nmake pasta\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		unit := detectIndent([]byte(tc.In), IndentTab)
+		b := repairList([]byte(tc.In), unit)
+		assert.Equal(tc.Exp, string(b), tc.In)
+		assert.Equal([]Problem{}, checkList(b, unit), tc.In)
+	}
+}
+
+func TestBasicTaskstore_CheckList(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	rslt, err := ts.CheckList("make_pasta")
+	assert.Nil(err)
+	assert.Equal(0, len(rslt.Problems))
+	assert.Equal(0, len(rslt.Diff))
+
+	rslt, err = ts.CheckList("malformed/excess_delta_indent")
+	assert.Nil(err)
+	assert.Equal(1, len(rslt.Problems))
+	assert.Equal([]string{
+		"-\t\t\tthis one is double-indented oh no!",
+		"+\t\tthis one is double-indented oh no!",
+		" \tthis one is okay",
+		" mmhmm",
+	}, rslt.Diff)
+
+	rslt, err = ts.CheckList("malformed/no_trailing_newline")
+	assert.Nil(err)
+	assert.Equal(1, len(rslt.Problems))
+	assert.Equal([]string{
+		" boil water",
+		" \tput pasta in water",
+		" make pasta",
+		`\ No newline at end of original file`,
+	}, rslt.Diff)
+
+	_, err = ts.CheckList("malformed/missing")
+	assert.NotNil(err)
+}
+
+func TestBasicTaskstore_RepairList(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	rslt, err := ts.CheckList("malformed/excess_delta_indent")
+	assert.Nil(err)
+
+	// The list has changed since it was checked
+	err = ts.RepairList("malformed/excess_delta_indent", StateID([]byte("something else")))
+	assert.Equal(ErrStaleWrite, err)
+
+	err = ts.RepairList("malformed/excess_delta_indent", rslt.StateID)
+	assert.Nil(err)
+	taskList, err := ts.GetList("malformed/excess_delta_indent")
+	assert.Nil(err)
+	assert.Equal("this one is double-indented oh no!", taskList[0].RootNode.Children[0].Children[0].Referent)
+	evs := ts.Feed().Since("malformed/excess_delta_indent", 0, 0)
+	assert.Equal(1, len(evs))
+	assert.Equal("RepairList", evs[0].Op)
+}
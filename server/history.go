@@ -0,0 +1,209 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/danslimmon/impulse/analytics"
+	"github.com/danslimmon/impulse/common"
+)
+
+// historyTimeFormat is the format of the timestamp at the beginning of each line of the history
+// file. Timestamps are in local time.
+const historyTimeFormat = "2006-01-02T15:04:05"
+
+// historyKinds are the kinds of event recorded in the history file, each of which begins its tag.
+var historyKinds = map[string]bool{
+	"archive": true,
+	"push":    true,
+	"focus":   true,
+	"move":    true,
+	// See interruptHistoryLine
+	"interrupt": true,
+}
+
+// historyEntry is a parsed line of the history file.
+type historyEntry struct {
+	Time time.Time
+	// Kind is the first word of the line's tag, e.g. `archive` (see historyLine) or `focus` (see
+	// focusHistoryLine), and Args are the rest of the words.
+	Kind string
+	Args []string
+	// Text is the rest of the line after the tag.
+	Text string
+}
+
+// historyTimestamp matches the timestamp (see historyTimeFormat) that begins each history entry,
+// along with the space that follows it.
+var historyTimestamp = regexp.MustCompile(`\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d `)
+
+// splitHistory splits b, the contents of the history file, into entries.
+//
+// Entries are newline-terminated lines, except those written before archived lines were tagged:
+// they weren't terminated at all, so a run of them forms a single line, which is split before each
+// timestamp. Tagged lines are never split, since an archived line may contain a timestamp of its
+// own.
+func splitHistory(b []byte) [][]byte {
+	rslt := make([][]byte, 0)
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		entry, err := parseHistoryLine(line)
+		if err != nil || entry.Kind != "archive" || len(entry.Args) > 0 {
+			rslt = append(rslt, line)
+			continue
+		}
+		start := 0
+		for _, loc := range historyTimestamp.FindAllIndex(line, -1) {
+			if loc[0] > start {
+				rslt = append(rslt, line[start:loc[0]])
+				start = loc[0]
+			}
+		}
+		rslt = append(rslt, line[start:])
+	}
+	return rslt
+}
+
+// parseHistoryLine parses a line of the history file.
+//
+// Lines written before archived lines were tagged consist of just a timestamp and the archived
+// line. They're parsed as `archive` entries without Args. See splitHistory for how they're told
+// apart from one another.
+func parseHistoryLine(line []byte) (*historyEntry, error) {
+	parts := strings.SplitN(string(line), " ", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("malformed history line `%s`", string(line))
+	}
+	t, err := time.ParseInLocation(historyTimeFormat, parts[0], time.Local)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &historyEntry{Time: t, Kind: "archive", Args: []string{}, Text: parts[1]}
+	if !strings.HasPrefix(parts[1], "[") {
+		return entry, nil
+	}
+	end := strings.Index(parts[1], "]")
+	if end == -1 {
+		return entry, nil
+	}
+	words := strings.Fields(parts[1][1:end])
+	if len(words) == 0 || !historyKinds[words[0]] {
+		return entry, nil
+	}
+	entry.Kind = words[0]
+	entry.Args = words[1:]
+	entry.Text = strings.TrimPrefix(parts[1][end+1:], " ")
+	return entry, nil
+}
+
+// appendHistory appends entry, a line produced by historyLine, pushHistoryLine, etc., to the
+// history file. Every entry is written as a single newline-terminated line, whatever its kind.
+//
+// If the file ends with an unterminated legacy entry (see splitHistory), the new entry is put on a
+// line of its own anyway.
+func (ts *BasicTaskstore) appendHistory(entry []byte) error {
+	if !bytes.HasSuffix(entry, []byte("\n")) {
+		entry = append(entry, '\n')
+	}
+	if b, err := ts.datastore.Get("history"); err == nil && len(b) > 0 && !bytes.HasSuffix(b, []byte("\n")) {
+		entry = append([]byte("\n"), entry...)
+	}
+	return ts.datastore.Append("history", entry)
+}
+
+// pushHistoryLine returns a line for the history file recording that n was pushed, at t, onto the
+// list identified by listName. Push history lines are of the form:
+//
+//	2021-12-30T19:24:48 [push make_pasta] boil water
+//
+// where the referent is escaped as it would be in the list's data, so that it can be matched up
+// with the line that's eventually archived.
+func pushHistoryLine(listName string, n *common.TreeNode, t time.Time) []byte {
+	return []byte(fmt.Sprintf("%s [push %s] %s\n", t.Format(historyTimeFormat), listName, escapeReferent(n.Referent)))
+}
+
+// Stats computes productivity statistics (see analytics.Compute) for the period from since until
+// now, from the history file and the timelog.
+//
+// A completion's push time is known if the history file records a push, onto the same list, of a
+// node whose referent is the same as that of the archived line. A node that was moved between lists
+// (see MoveToList) keeps its push time.
+func (ts *BasicTaskstore) Stats(since time.Time) (*analytics.Stats, error) {
+	completions := make([]analytics.Completion, 0)
+	b, err := ts.datastore.Get("history")
+	if err != nil {
+		// No history file yet
+		b = []byte{}
+	}
+	// pushes holds, for each list and referent, the times of the pushes that haven't been matched up
+	// with archives yet, oldest first.
+	pushes := make(map[string][]time.Time)
+	pushKey := func(listName, text string) string {
+		return listName + "\n" + text
+	}
+	for _, line := range splitHistory(b) {
+		entry, err := parseHistoryLine(line)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case entry.Kind == "push" && len(entry.Args) == 1:
+			k := pushKey(entry.Args[0], entry.Text)
+			pushes[k] = append(pushes[k], entry.Time)
+		case entry.Kind == "move" && len(entry.Args) == 4 && entry.Args[0] == "in":
+			// [move in DEST from SRC] referent
+			src := pushKey(entry.Args[3], entry.Text)
+			if len(pushes[src]) > 0 {
+				dest := pushKey(entry.Args[1], entry.Text)
+				pushes[dest] = append(pushes[dest], pushes[src][0])
+				pushes[src] = pushes[src][1:]
+			}
+		case entry.Kind == "archive":
+			c := analytics.Completion{Time: entry.Time}
+			if len(entry.Args) > 0 {
+				c.ListName = entry.Args[0]
+			}
+			k := pushKey(c.ListName, strings.TrimLeft(entry.Text, " \t"))
+			if len(pushes[k]) > 0 {
+				c.Pushed = pushes[k][0]
+				pushes[k] = pushes[k][1:]
+			}
+			completions = append(completions, c)
+		}
+	}
+
+	samples, err := ts.depthSamples()
+	if err != nil {
+		return nil, err
+	}
+	return analytics.Compute(completions, samples, since, time.Now()), nil
+}
+
+// depthSamples returns a sample of the depth of a list's top frame for each line of the timelog.
+// Malformed lines are skipped.
+func (ts *BasicTaskstore) depthSamples() ([]analytics.DepthSample, error) {
+	ts.timelogMu.Lock()
+	defer ts.timelogMu.Unlock()
+
+	rslt := make([]analytics.DepthSample, 0)
+	b, err := ts.datastore.Get(timelogName)
+	if err != nil {
+		// No timelog yet
+		return rslt, nil
+	}
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		t, listName, path, err := parseTimelogLine(line)
+		if err != nil {
+			continue
+		}
+		rslt = append(rslt, analytics.DepthSample{ListName: listName, Time: t, Depth: len(path)})
+	}
+	return rslt, nil
+}
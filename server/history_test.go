@@ -0,0 +1,170 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHistoryLine(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	entry, err := parseHistoryLine([]byte("2021-12-30T19:24:48 [archive make_pasta] \t\tput water in pot"))
+	assert.Nil(err)
+	assert.Equal(time.Date(2021, time.December, 30, 19, 24, 48, 0, time.Local), entry.Time)
+	assert.Equal("archive", entry.Kind)
+	assert.Equal([]string{"make_pasta"}, entry.Args)
+	assert.Equal("\t\tput water in pot", entry.Text)
+
+	entry, err = parseHistoryLine([]byte("2021-12-30T19:24:48 [focus completed 25m0s] make pasta > boil water"))
+	assert.Nil(err)
+	assert.Equal("focus", entry.Kind)
+	assert.Equal([]string{"completed", "25m0s"}, entry.Args)
+	assert.Equal("make pasta > boil water", entry.Text)
+
+	// Archived lines used to be recorded without a tag, and a blocker isn't a tag
+	entry, err = parseHistoryLine([]byte("2021-12-30T19:24:48 [b cooked]"))
+	assert.Nil(err)
+	assert.Equal("archive", entry.Kind)
+	assert.Equal([]string{}, entry.Args)
+	assert.Equal("[b cooked]", entry.Text)
+
+	_, err = parseHistoryLine([]byte(""))
+	assert.NotNil(err)
+	_, err = parseHistoryLine([]byte("yesterday [archive make_pasta] make pasta"))
+	assert.NotNil(err)
+}
+
+// Tests that each kind of history entry gets a line of its own.
+func TestBasicTaskstore_appendHistory(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	_, err := ts.StartFocus("make_pasta", time.Hour)
+	assert.Nil(err)
+	err = ts.ArchiveLine(common.GetLineID("make_pasta", "\t\tput water in pot"))
+	assert.Nil(err)
+	err = ts.InsertTask(common.LineID("make_pasta:0"), common.NewTask(common.NewTreeNode("check Twitter")))
+	assert.Nil(err)
+	err = ts.appendHistory([]byte("2021-12-30T19:24:48 [archive make_pasta] no newline"))
+	assert.Nil(err)
+
+	b, err := ds.Get("history")
+	assert.Nil(err)
+	kinds := make([]string, 0)
+	for _, line := range strings.Split(strings.TrimSuffix(string(b), "\n"), "\n") {
+		entry, err := parseHistoryLine([]byte(line))
+		if assert.Nil(err) {
+			kinds = append(kinds, entry.Kind)
+		}
+	}
+	assert.Equal([]string{"archive", "focus", "push", "interrupt", "archive"}, kinds)
+}
+
+func TestSplitHistory(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	// Untagged lines used to be written without a newline
+	b := []byte("2021-12-30T19:24:48 \t\tput water in pot2021-12-30T19:25:00 [b cooked]" +
+		"2021-12-30T19:26:00 [archive make_pasta] drain pasta\n" +
+		"2021-12-30T19:27:00 [push make_pasta] eat 2021-12-30T19:28:00 pasta\n")
+	lines := make([]string, 0)
+	for _, line := range splitHistory(b) {
+		lines = append(lines, string(line))
+	}
+	assert.Equal([]string{
+		"2021-12-30T19:24:48 \t\tput water in pot",
+		"2021-12-30T19:25:00 [b cooked]",
+		"2021-12-30T19:26:00 [archive make_pasta] drain pasta",
+		// Tagged lines are left alone
+		"2021-12-30T19:27:00 [push make_pasta] eat 2021-12-30T19:28:00 pasta",
+		"",
+	}, lines)
+}
+
+// Tests that a history file written before entries were newline-terminated can still be read, and
+// appended to.
+func TestBasicTaskstore_LegacyHistory(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	err := ds.Put("history", []byte("2021-12-30T19:24:48 \t\tput pot in sink2021-12-30T19:25:00 \t\tput lid on pot"))
+	assert.Nil(err)
+	err = ts.ArchiveLine(common.GetLineID("make_pasta", "\t\tput water in pot"))
+	assert.Nil(err)
+
+	b, err := ds.Get("history")
+	assert.Nil(err)
+	assert.True(strings.HasPrefix(string(b), "2021-12-30T19:24:48 \t\tput pot in sink2021-12-30T19:25:00 \t\tput lid on pot\n"))
+
+	rslt, err := ts.Search("put", SearchSubstring, true)
+	assert.Nil(err)
+	paths := make([][]string, 0)
+	times := make([]time.Time, 0)
+	for _, r := range rslt {
+		if r.ListName == "history" {
+			paths = append(paths, r.Path)
+			times = append(times, r.Time)
+		}
+	}
+	assert.Equal([][]string{{"put pot in sink"}, {"put lid on pot"}, {"put water in pot"}}, paths)
+	if assert.Equal(3, len(times)) {
+		assert.Equal(time.Date(2021, 12, 30, 19, 24, 48, 0, time.Local), times[0])
+		assert.Equal(time.Date(2021, 12, 30, 19, 25, 0, 0, time.Local), times[1])
+		assert.True(time.Since(times[2]) < time.Minute)
+	}
+}
+
+func TestBasicTaskstore_Stats(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	today := time.Now()
+	yesterday := today.AddDate(0, 0, -1)
+	ds.Append("history", pushHistoryLine("make_pasta", common.NewTreeNode("salt water"), today.Add(-time.Hour)))
+	ds.Append("history", ts.historyLine("make_pasta", []byte("\tsalt water")))
+	ds.Append("history", []byte(yesterday.Format(historyTimeFormat)+" an old-style line\n"))
+	ds.Append("history", focusHistoryLine(&FocusSession{Path: []string{"make pasta"}, Start: today}, FocusAbandoned, today))
+
+	err := ts.InsertTask(common.LineID("multiple_nested:0"), common.NewTask(common.NewTreeNode("task -1")))
+	assert.Nil(err)
+	err = ts.MoveToList(common.GetLineID("multiple_nested", "task -1"), common.LineID("make_pasta:0"))
+	assert.Nil(err)
+	err = ts.ArchiveLine(common.GetLineID("make_pasta", "task -1"))
+	assert.Nil(err)
+
+	stats, err := ts.Stats(yesterday)
+	assert.Nil(err)
+	assert.Equal(2, len(stats.Days))
+	assert.Equal(1, stats.Days[0].Completions)
+	assert.Equal(2, stats.Days[1].Completions)
+	assert.Equal(3, stats.Completions)
+	assert.Equal(2, len(stats.Lists))
+	assert.Equal("", stats.Lists[0].ListName)
+	assert.Equal(time.Duration(0), stats.Lists[0].AvgTimeToArchive)
+	assert.Equal("make_pasta", stats.Lists[1].ListName)
+	assert.Equal(2, stats.Lists[1].Completions)
+	// One push happened an hour before the archive, and the other just now (give or take rounding
+	// to the second)
+	assert.True(stats.Lists[1].AvgTimeToArchive > 29*time.Minute)
+	assert.True(stats.Lists[1].AvgTimeToArchive < 31*time.Minute)
+	// The timelog saw the top frames of multiple_nested and make_pasta change
+	assert.Equal(3, stats.MaxDepth)
+}
@@ -0,0 +1,289 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// hoistsName is the name of the Datastore file in which each client's hoist is stored.
+const hoistsName = "hoists"
+
+// Hoist is a client's zoom into a subtree of a list. While a client has a hoist, its push, pop, and
+// top operations on the list (see Push, Pop, and common.Top) are scoped to the hoisted subtree.
+//
+// Each client (e.g. the CLI, or a particular TUI) has at most one hoist, which persists until the
+// client unhoists.
+type Hoist struct {
+	Client   string
+	ListName string
+	// Path is the path (see TimeInterval) to the hoisted node. The elements before the last are its
+	// ancestors, so they make up a breadcrumb.
+	Path []string
+	// LineID is the ID of the hoisted node's line.
+	LineID common.LineID
+}
+
+// marshal returns the line of the hoists file that represents h.
+//
+// It consists of tab-separated fields: the client, the list name, and then the escaped elements of
+// the path (see escapePath).
+func (h *Hoist) marshal() []byte {
+	fields := append([]string{h.Client, h.ListName}, escapePath(h.Path)...)
+	return []byte(strings.Join(fields, "\t") + "\n")
+}
+
+// unmarshalHoists parses the contents of the hoists file. LineID isn't stored, so it's left empty.
+func unmarshalHoists(b []byte) ([]*Hoist, error) {
+	rslt := make([]*Hoist, 0)
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		fields := strings.Split(string(line), "\t")
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("malformed hoist `%s`", string(line))
+		}
+		rslt = append(rslt, &Hoist{Client: fields[0], ListName: fields[1], Path: unescapePath(fields[2:])})
+	}
+	return rslt, nil
+}
+
+// readHoists returns every client's hoist.
+//
+// The caller must hold ts.hoistMu.
+func (ts *BasicTaskstore) readHoists() ([]*Hoist, error) {
+	b, err := ts.datastore.Get(hoistsName)
+	if err != nil {
+		// No hoists file yet
+		return []*Hoist{}, nil
+	}
+	return unmarshalHoists(b)
+}
+
+// writeHoist replaces client's hoist with h. If h is nil, client's hoist is removed.
+//
+// The caller must hold ts.hoistMu.
+func (ts *BasicTaskstore) writeHoist(client string, h *Hoist) error {
+	hoists, err := ts.readHoists()
+	if err != nil {
+		return err
+	}
+	var b []byte
+	for _, other := range hoists {
+		if other.Client != client {
+			b = append(b, other.marshal()...)
+		}
+	}
+	if h != nil {
+		b = append(b, h.marshal()...)
+	}
+	return ts.datastore.Put(hoistsName, b)
+}
+
+// followHoists updates the hoists whose paths pass through the node identified by oldId in oldB,
+// the list identified by listName's data before a line was edited, so that they pass through the
+// node identified by newId in newB, its data afterward. Otherwise hoists would be lost whenever a
+// hoisted node, or one of its ancestors, was renamed, snoozed, re-tagged, etc.
+func (ts *BasicTaskstore) followHoists(listName string, oldB []byte, oldId common.LineID, newB []byte, newId common.LineID) {
+	oldList, err := ts.unmarshalList(listName, oldB)
+	if err != nil {
+		return
+	}
+	newList, err := ts.unmarshalList(listName, newB)
+	if err != nil {
+		return
+	}
+	oldNode, newNode := ts.findNode(listName, oldList, oldId), ts.findNode(listName, newList, newId)
+	if oldNode == nil || newNode == nil {
+		return
+	}
+	oldPath, newPath := nodePath(oldNode), nodePath(newNode)
+	if pathsEqual(oldPath, newPath) {
+		return
+	}
+
+	ts.hoistMu.Lock()
+	defer ts.hoistMu.Unlock()
+	hoists, err := ts.readHoists()
+	if err != nil {
+		return
+	}
+	for _, h := range hoists {
+		if h.ListName != listName || !hasPrefix(h.Path, oldPath) {
+			continue
+		}
+		h.Path = append(append([]string{}, newPath...), h.Path[len(oldPath):]...)
+		ts.writeHoist(h.Client, h)
+	}
+}
+
+// hoistedNode returns client's hoist along with the hoisted node, in the list's current state.
+//
+// If client has no hoist, nil is returned for both. If the hoisted node no longer exists (e.g.
+// because it's been archived), the hoist is removed, and nil is returned for both.
+//
+// The caller must hold ts.hoistMu.
+func (ts *BasicTaskstore) hoistedNode(client string) (*Hoist, *common.TreeNode, error) {
+	hoists, err := ts.readHoists()
+	if err != nil {
+		return nil, nil, err
+	}
+	var h *Hoist
+	for _, other := range hoists {
+		if other.Client == client {
+			h = other
+		}
+	}
+	if h == nil {
+		return nil, nil, nil
+	}
+
+	taskList, err := ts.GetList(h.ListName)
+	if _, ok := err.(*NoSuchListError); ok {
+		return nil, nil, ts.writeHoist(client, nil)
+	} else if err != nil {
+		return nil, nil, err
+	}
+	n := findPath(taskList, h.Path)
+	if n == nil {
+		return nil, nil, ts.writeHoist(client, nil)
+	}
+	h.LineID = ts.nodeLineId(h.ListName, n)
+	return h, n, nil
+}
+
+// GetHoist returns client's hoist along with the hoisted subtree, or nil for both if client has no
+// hoist.
+func (ts *BasicTaskstore) GetHoist(client string) (*Hoist, *common.Task, error) {
+	ts.hoistMu.Lock()
+	defer ts.hoistMu.Unlock()
+
+	h, n, err := ts.hoistedNode(client)
+	if err != nil || h == nil {
+		return nil, nil, err
+	}
+	return h, common.NewTask(n), nil
+}
+
+// SetHoist hoists the node identified by lineId for client, replacing any hoist client already has.
+func (ts *BasicTaskstore) SetHoist(client string, lineId common.LineID) (*Hoist, error) {
+	if client == "" || strings.ContainsAny(client, "\t\n") {
+		return nil, fmt.Errorf("invalid client name `%s`", client)
+	}
+	listName, _, err := ts.splitLineId(lineId)
+	if err != nil {
+		return nil, err
+	}
+	taskList, err := ts.GetList(listName)
+	if err != nil {
+		return nil, err
+	}
+	n := ts.findNode(listName, taskList, lineId)
+	if n == nil {
+		return nil, fmt.Errorf("no node with ID `%s`", string(lineId))
+	}
+
+	ts.hoistMu.Lock()
+	defer ts.hoistMu.Unlock()
+	h := &Hoist{Client: client, ListName: listName, Path: nodePath(n), LineID: lineId}
+	if err := ts.writeHoist(client, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Unhoist removes client's hoist, if it has one.
+func (ts *BasicTaskstore) Unhoist(client string) error {
+	ts.hoistMu.Lock()
+	defer ts.hoistMu.Unlock()
+	return ts.writeHoist(client, nil)
+}
+
+// scope returns the part of taskList, the list identified by listName, to which client's push, pop,
+// and top operations are scoped: the hoisted subtree if client has a hoist on the list, or else the
+// whole list. The hoisted node is returned too, or nil if there's no hoist.
+//
+// The caller must hold ts.hoistMu.
+func (ts *BasicTaskstore) scope(client, listName string, taskList []*common.Task) ([]*common.Task, *common.TreeNode, error) {
+	h, _, err := ts.hoistedNode(client)
+	if err != nil {
+		return nil, nil, err
+	}
+	if h == nil || h.ListName != listName {
+		return taskList, nil, nil
+	}
+	n := findPath(taskList, h.Path)
+	if n == nil {
+		return taskList, nil, nil
+	}
+	return []*common.Task{common.NewTask(n)}, n, nil
+}
+
+// Push puts task on top of the list identified by listName. If client has hoisted a node in the
+// list, task becomes the node's first child, so that it's on top of the hoisted subtree; otherwise
+// it becomes the first task in the list.
+func (ts *BasicTaskstore) Push(client, listName string, task *common.Task) (common.LineID, error) {
+	if err := validateTask(task); err != nil {
+		return "", err
+	}
+	b, err := ts.readList(listName)
+	if err != nil {
+		return "", err
+	}
+	taskList, err := ts.unmarshalList(listName, b)
+	if err != nil {
+		return "", err
+	}
+
+	ts.hoistMu.Lock()
+	_, hoisted, err := ts.scope(client, listName, taskList)
+	ts.hoistMu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	prevTop := common.Top(taskList)
+	if hoisted == nil {
+		taskList = append([]*common.Task{task}, taskList...)
+	} else {
+		hoisted.InsertChild(0, task.RootNode)
+	}
+
+	pushedId := ts.nodeLineId(listName, task.RootNode)
+	if err := ts.putList(listName, taskList, StateID(b), "Push", pushedId); err != nil {
+		return "", err
+	}
+	ts.appendHistory(pushHistoryLine(listName, task.RootNode, time.Now()))
+	ts.recordInterrupt(listName, prevTop, taskList, task.RootNode)
+	return pushedId, nil
+}
+
+// Pop archives the top frame (see common.Top) of the list identified by listName, scoped to
+// client's hoist if it has one on the list. It returns the path to the archived node.
+//
+// If the hoisted node has no children left, it's the top frame, so popping it ends the hoist.
+func (ts *BasicTaskstore) Pop(client, listName string) ([]string, error) {
+	taskList, err := ts.GetList(listName)
+	if err != nil {
+		return nil, err
+	}
+
+	ts.hoistMu.Lock()
+	scoped, _, err := ts.scope(client, listName, taskList)
+	ts.hoistMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	top := common.Top(scoped)
+	if top == nil {
+		return nil, fmt.Errorf("list `%s` has no top frame to pop", listName)
+	}
+
+	if err := ts.ArchiveLine(ts.nodeLineId(listName, top)); err != nil {
+		return nil, err
+	}
+	return nodePath(top), nil
+}
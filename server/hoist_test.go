@@ -0,0 +1,187 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicTaskstore_Hoist(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ts, cleanup := NewBasicTaskstoreWithTestdata()
+	defer cleanup()
+
+	h, task, err := ts.GetHoist("cli")
+	assert.Nil(err)
+	assert.Nil(h)
+	assert.Nil(task)
+
+	boilWater := common.GetLineID("make_pasta", "\tboil water")
+	h, err = ts.SetHoist("cli", boilWater)
+	assert.Nil(err)
+	assert.Equal([]string{"make pasta", "boil water"}, h.Path)
+
+	// Hoists are per client
+	h, _, err = ts.GetHoist("tui")
+	assert.Nil(err)
+	assert.Nil(h)
+
+	h, task, err = ts.GetHoist("cli")
+	assert.Nil(err)
+	assert.Equal("make_pasta", h.ListName)
+	assert.Equal(boilWater, h.LineID)
+	assert.Equal("boil water", task.RootNode.Referent)
+	assert.Equal(3, len(task.RootNode.Children))
+
+	// The hoist file isn't a list
+	names, err := ts.ListNames()
+	assert.Nil(err)
+	assert.NotContains(names, "hoists")
+
+	err = ts.Unhoist("cli")
+	assert.Nil(err)
+	h, _, err = ts.GetHoist("cli")
+	assert.Nil(err)
+	assert.Nil(h)
+
+	_, err = ts.SetHoist("cli", common.GetLineID("make_pasta", "nonexistent"))
+	assert.NotNil(err)
+}
+
+func TestBasicTaskstore_Hoist_Archived(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ts, cleanup := NewBasicTaskstoreWithTestdata()
+	defer cleanup()
+
+	lineId := common.GetLineID("multiple_nested", "task 1")
+	_, err := ts.SetHoist("cli", lineId)
+	assert.Nil(err)
+	err = ts.ArchiveLine(lineId)
+	assert.Nil(err)
+
+	// Once the hoisted node is gone, so is the hoist
+	h, _, err := ts.GetHoist("cli")
+	assert.Nil(err)
+	assert.Nil(h)
+}
+
+// Tests that a hoist survives edits to the hoisted node's line and to its ancestors' lines.
+func TestBasicTaskstore_Hoist_Edited(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ts, cleanup := NewBasicTaskstoreWithTestdata()
+	defer cleanup()
+
+	_, err := ts.SetHoist("cli", common.GetLineID("make_pasta", "\tboil water"))
+	assert.Nil(err)
+	_, err = ts.SetHoist("tui", common.GetLineID("multiple_nested", "task 1"))
+	assert.Nil(err)
+
+	// Snoozing the hoisted node changes its line
+	until := time.Date(2030, 1, 2, 3, 4, 0, 0, time.Local)
+	lineId, err := ts.Snooze(common.GetLineID("make_pasta", "\tboil water"), until)
+	assert.Nil(err)
+	h, task, err := ts.GetHoist("cli")
+	assert.Nil(err)
+	if assert.NotNil(h) {
+		assert.Equal(lineId, h.LineID)
+		assert.Equal(3, len(task.RootNode.Children))
+	}
+
+	// So does tagging one of its ancestors
+	_, err = ts.SetMetadata(common.GetLineID("make_pasta", "make pasta"), common.Metadata{Tags: []string{"kitchen"}})
+	assert.Nil(err)
+	h, task, err = ts.GetHoist("cli")
+	assert.Nil(err)
+	if assert.NotNil(h) {
+		assert.Equal("make pasta #kitchen", h.Path[0])
+		assert.Equal(3, len(task.RootNode.Children))
+	}
+
+	// Other clients' hoists, on other lists, are left alone
+	h, _, err = ts.GetHoist("tui")
+	assert.Nil(err)
+	if assert.NotNil(h) {
+		assert.Equal([]string{"task 1"}, h.Path)
+	}
+}
+
+// Tests that a hoisted node whose referent contains tabs and newlines can be found again.
+func TestBasicTaskstore_Hoist_Escaping(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ts, cleanup := NewBasicTaskstoreWithTestdata()
+	defer cleanup()
+
+	referent := "buy:\n- eggs\t(6)"
+	err := ts.InsertTask(common.LineID("make_pasta:0"), common.NewTask(common.NewTreeNode(referent)))
+	assert.Nil(err)
+	lineId := common.GetLineID("make_pasta", `buy:\n- eggs\t(6)`)
+	_, err = ts.SetHoist("cli", lineId)
+	assert.Nil(err)
+
+	h, task, err := ts.GetHoist("cli")
+	assert.Nil(err)
+	if assert.NotNil(h) {
+		assert.Equal([]string{referent}, h.Path)
+		assert.Equal(lineId, h.LineID)
+		assert.Equal(referent, task.RootNode.Referent)
+	}
+}
+
+func TestBasicTaskstore_PushPop(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ts, cleanup := NewBasicTaskstoreWithTestdata()
+	defer cleanup()
+
+	// Without a hoist, push and pop act on the whole list
+	_, err := ts.Push("cli", "multiple_nested", common.NewTask(common.NewTreeNode("task -1")))
+	assert.Nil(err)
+	taskList, err := ts.GetList("multiple_nested")
+	assert.Nil(err)
+	assert.Equal("task -1", taskList[0].RootNode.Referent)
+	path, err := ts.Pop("cli", "multiple_nested")
+	assert.Nil(err)
+	assert.Equal([]string{"task -1"}, path)
+
+	// With a hoist, they act on the hoisted subtree
+	_, err = ts.SetHoist("cli", common.GetLineID("multiple_nested", "task 1"))
+	assert.Nil(err)
+	lineId, err := ts.Push("cli", "multiple_nested", common.NewTask(common.NewTreeNode("subtask 0.5")))
+	assert.Nil(err)
+	assert.Equal(common.GetLineID("multiple_nested", "\tsubtask 0.5"), lineId)
+	taskList, err = ts.GetList("multiple_nested")
+	assert.Nil(err)
+	assert.Equal([]string{"task 0", "subtask 0", "subsubtask 0"}, topPath(taskList))
+	assert.Equal("subtask 0.5", taskList[1].RootNode.Children[0].Referent)
+
+	path, err = ts.Pop("cli", "multiple_nested")
+	assert.Nil(err)
+	assert.Equal([]string{"task 1", "subtask 0.5"}, path)
+	path, err = ts.Pop("cli", "multiple_nested")
+	assert.Nil(err)
+	assert.Equal([]string{"task 1", "subtask 1"}, path)
+
+	// Another client's hoist doesn't affect this one
+	path, err = ts.Pop("tui", "multiple_nested")
+	assert.Nil(err)
+	assert.Equal([]string{"task 0", "subtask 0", "subsubtask 0"}, path)
+
+	// Popping the hoisted node itself ends the hoist
+	path, err = ts.Pop("cli", "multiple_nested")
+	assert.Nil(err)
+	assert.Equal([]string{"task 1"}, path)
+	h, _, err := ts.GetHoist("cli")
+	assert.Nil(err)
+	assert.Nil(h)
+}
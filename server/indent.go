@@ -0,0 +1,85 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// The indent units that the basic format accepts. Within a file, every level of indentation is
+// represented by the same unit.
+const (
+	IndentTab     = "\t"
+	Indent2Spaces = "  "
+	Indent4Spaces = "    "
+)
+
+// ParseIndent returns the indent unit named by s, which is one of "tab", "2", or "4" (the number of
+// spaces).
+func ParseIndent(s string) (string, error) {
+	switch s {
+	case "tab":
+		return IndentTab, nil
+	case "2":
+		return Indent2Spaces, nil
+	case "4":
+		return Indent4Spaces, nil
+	}
+	return "", fmt.Errorf("unknown indent unit `%s`; must be `tab`, `2`, or `4`", s)
+}
+
+// detectIndent determines the indent unit in which b, the basic-format data of a task list, is
+// written.
+//
+// If any line is indented with a tab, the unit is a tab. Otherwise it's the shallowest indentation
+// that occurs (since a line indented two levels must be above one indented one level), as long as
+// that's 2 or 4 spaces. If no line is indented, def is returned.
+func detectIndent(b []byte, def string) string {
+	minSpaces := 0
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if bytes.HasPrefix(line, []byte("\t")) {
+			return IndentTab
+		}
+		n := len(line) - len(bytes.TrimLeft(line, " "))
+		if n > 0 && n < len(line) && (minSpaces == 0 || n < minSpaces) {
+			minSpaces = n
+		}
+	}
+
+	switch {
+	case minSpaces == 0:
+		return def
+	case minSpaces%4 == 0:
+		return Indent4Spaces
+	case minSpaces%2 == 0:
+		return Indent2Spaces
+	}
+	return def
+}
+
+// splitIndent returns the indent level of line, in which each level is represented by unit, and the
+// remaining text of the line.
+//
+// Leading whitespace that doesn't make up a whole unit is left in the text.
+func splitIndent(line, unit string) (int, string) {
+	indent := 0
+	for strings.HasPrefix(line, unit) {
+		line = line[len(unit):]
+		indent++
+	}
+	return indent, line
+}
+
+// canonicalLine returns line, whose indentation is in unit, with its indentation converted to tabs
+// and, unless it's a comment or note line, its text escaped as escapeReferent would escape it.
+//
+// Line IDs are calculated from canonical lines, so that they don't depend on the indentation style
+// of the file, and so that a hand-written line that isn't escaped the way we'd write it (e.g.
+// `C:\path`, whose backslash isn't escaped) has the same ID as its node (see nodeLineId).
+func canonicalLine(line, unit string) string {
+	indent, text := splitIndent(line, unit)
+	if !isCommentLine(text) && !isNoteLine(text) {
+		text = escapeReferent(unescapeReferent(text))
+	}
+	return strings.Repeat("\t", indent) + text
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_detectIndent(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	type testCase struct {
+		In  string
+		Exp string
+	}
+
+	testCases := []testCase{
+		testCase{In: "\t\tput water in pot\n\tboil water\nmake pasta\n", Exp: IndentTab},
+		testCase{In: "    put water in pot\n  boil water\nmake pasta\n", Exp: Indent2Spaces},
+		testCase{In: "        put water in pot\n    boil water\nmake pasta\n", Exp: Indent4Spaces},
+		// a tab anywhere wins
+		testCase{In: "  put water in pot\n\tboil water\nmake pasta\n", Exp: IndentTab},
+		// whitespace-only lines don't count
+		testCase{In: "  boil water\n    \nmake pasta\n", Exp: Indent2Spaces},
+		// neither 2 nor 4 spaces, so the default
+		testCase{In: "   boil water\nmake pasta\n", Exp: IndentTab},
+		testCase{In: "make pasta\n", Exp: IndentTab},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(tc.Exp, detectIndent([]byte(tc.In), IndentTab), tc.In)
+	}
+	assert.Equal(Indent4Spaces, detectIndent([]byte("make pasta\n"), Indent4Spaces))
+}
+
+func Test_splitIndent(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	indent, text := splitIndent("\t\tput water in pot", IndentTab)
+	assert.Equal(2, indent)
+	assert.Equal("put water in pot", text)
+
+	indent, text = splitIndent("     put water in pot", Indent2Spaces)
+	assert.Equal(2, indent)
+	assert.Equal(" put water in pot", text)
+
+	assert.Equal("\t\tput water in pot", canonicalLine("        put water in pot", Indent4Spaces))
+}
+
+func TestParseIndent(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	unit, err := ParseIndent("tab")
+	assert.Nil(err)
+	assert.Equal(IndentTab, unit)
+	unit, err = ParseIndent("2")
+	assert.Nil(err)
+	assert.Equal(Indent2Spaces, unit)
+	_, err = ParseIndent("3")
+	assert.NotNil(err)
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danslimmon/impulse/analytics"
+	"github.com/danslimmon/impulse/common"
+)
+
+// interruptedFrame determines whether pushing the node pushed onto taskList interrupted prevTop,
+// the frame that was on top before the push. If so, it returns prevTop; otherwise it returns nil.
+//
+// A push is an interrupt if it puts a new frame on top, and the pushed node isn't a child of the
+// frame that was on top. If it is a child, it's a planned substep: e.g., `boil water` pushed onto
+// `make pasta`. But if, while waiting for the water to boil, `check Twitter` is pushed onto the top
+// of the list (or anywhere else that isn't under `wait for water to boil`), it's an interrupt.
+func interruptedFrame(prevTop *common.TreeNode, taskList []*common.Task, pushed *common.TreeNode) *common.TreeNode {
+	if prevTop == nil || pushed.Parent == prevTop {
+		return nil
+	}
+	for n := common.Top(taskList); n != nil; n = n.Parent {
+		if n == pushed {
+			return prevTop
+		}
+	}
+	return nil
+}
+
+// interruptHistoryLine returns a line for the history file recording that the node at
+// interrupting interrupted the frame at interrupted, in the list identified by listName, at t.
+//
+// Interrupt history lines are of the form:
+//
+//	2021-12-30T19:24:48 [interrupt make_pasta 2] make pasta	wait for water to boil	check Twitter
+//
+// The elements of both paths are escaped (see escapeReferent) and separated by tabs, and the number
+// in the tag is the length of the interrupted path.
+func interruptHistoryLine(listName string, interrupted, interrupting []string, t time.Time) []byte {
+	escaped := make([]string, 0, len(interrupted)+len(interrupting))
+	for _, r := range append(append([]string{}, interrupted...), interrupting...) {
+		escaped = append(escaped, escapeReferent(r))
+	}
+	return []byte(fmt.Sprintf(
+		"%s [interrupt %s %d] %s\n",
+		t.Format(historyTimeFormat),
+		listName,
+		len(interrupted),
+		strings.Join(escaped, "\t"),
+	))
+}
+
+// parseInterrupt returns the interrupt recorded by entry, an `interrupt` entry in the history file
+// (see interruptHistoryLine). Its End is left zero.
+func parseInterrupt(entry *historyEntry) (*analytics.Interrupt, error) {
+	if len(entry.Args) != 2 {
+		return nil, fmt.Errorf("malformed interrupt tag `%s`", strings.Join(entry.Args, " "))
+	}
+	n, err := strconv.Atoi(entry.Args[1])
+	if err != nil {
+		return nil, err
+	}
+	path := strings.Split(entry.Text, "\t")
+	if n < 1 || n >= len(path) {
+		return nil, fmt.Errorf("malformed interrupt `%s`", entry.Text)
+	}
+	for i := range path {
+		path[i] = unescapeReferent(path[i])
+	}
+	return &analytics.Interrupt{
+		ListName:     entry.Args[0],
+		Interrupted:  path[:n],
+		Interrupting: path[n:],
+		Start:        entry.Time,
+	}, nil
+}
+
+// recordInterrupt records in the history file that pushing the node pushed onto taskList, the list
+// identified by listName, interrupted prevTop, if it did (see interruptedFrame).
+func (ts *BasicTaskstore) recordInterrupt(listName string, prevTop *common.TreeNode, taskList []*common.Task, pushed *common.TreeNode) {
+	interrupted := interruptedFrame(prevTop, taskList, pushed)
+	if interrupted == nil {
+		return
+	}
+	ts.appendHistory(interruptHistoryLine(listName, nodePath(interrupted), nodePath(pushed), time.Now()))
+}
+
+// hasPrefix determines whether path begins with the elements of prefix.
+func hasPrefix(path, prefix []string) bool {
+	return len(path) >= len(prefix) && pathsEqual(path[:len(prefix)], prefix)
+}
+
+// Interrupts returns the interrupts recorded in the history file, in chronological order.
+//
+// An interrupt ends when the timelog shows that its list's top frame is no longer the interrupting
+// node or one of its descendants: because it's been archived, say, or because something else has
+// interrupted it in turn. Malformed lines in the history file and the timelog are skipped.
+func (ts *BasicTaskstore) Interrupts() ([]analytics.Interrupt, error) {
+	rslt := make([]analytics.Interrupt, 0)
+	b, err := ts.datastore.Get("history")
+	if err != nil {
+		// No history file yet
+		return rslt, nil
+	}
+	for _, line := range splitHistory(b) {
+		entry, err := parseHistoryLine(line)
+		if err != nil || entry.Kind != "interrupt" {
+			continue
+		}
+		i, err := parseInterrupt(entry)
+		if err != nil {
+			continue
+		}
+		rslt = append(rslt, *i)
+	}
+
+	ts.timelogMu.Lock()
+	defer ts.timelogMu.Unlock()
+	b, err = ts.datastore.Get(timelogName)
+	if err != nil {
+		// No timelog yet
+		return rslt, nil
+	}
+	// started records, for each interrupt, whether the timelog has shown it on top yet.
+	started := make([]bool, len(rslt))
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		t, listName, path, err := parseTimelogLine(line)
+		if err != nil {
+			// Skip malformed timelog lines
+			continue
+		}
+		// The interrupt's history line is written just after the timelog line that shows it on top,
+		// so the timelog line may be up to a second older.
+		for j := range rslt {
+			i := &rslt[j]
+			if i.ListName != listName || !i.End.IsZero() || t.Before(i.Start.Add(-time.Second)) {
+				continue
+			}
+			onTop := hasPrefix(path, i.Interrupting)
+			if !started[j] {
+				started[j] = onTop
+			} else if !onTop {
+				i.End = t
+				if i.End.Before(i.Start) {
+					i.End = i.Start
+				}
+			}
+		}
+	}
+	return rslt, nil
+}
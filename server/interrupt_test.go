@@ -0,0 +1,81 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterruptHistoryLine(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	start := time.Date(2021, time.December, 30, 19, 24, 48, 0, time.Local)
+	line := interruptHistoryLine("dinner", []string{"cook pasta", "wait\tfor water"}, []string{"check Twitter"}, start)
+	assert.Equal("2021-12-30T19:24:48 [interrupt dinner 2] cook pasta\twait\\tfor water\tcheck Twitter\n", string(line))
+
+	entry, err := parseHistoryLine(line[:len(line)-1])
+	assert.Nil(err)
+	i, err := parseInterrupt(entry)
+	assert.Nil(err)
+	assert.Equal("dinner", i.ListName)
+	assert.Equal([]string{"cook pasta", "wait\tfor water"}, i.Interrupted)
+	assert.Equal([]string{"check Twitter"}, i.Interrupting)
+	assert.Equal(start, i.Start)
+}
+
+func TestBasicTaskstore_Interrupts(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ts, cleanup := NewBasicTaskstoreWithTestdata()
+	defer cleanup()
+
+	// Pushing a substep onto the top frame isn't an interrupt
+	_, err := ts.SetHoist("cli", common.GetLineID("multiple_nested", "\t\tsubsubtask 0"))
+	assert.Nil(err)
+	_, err = ts.Push("cli", "multiple_nested", common.NewTask(common.NewTreeNode("subsubsubtask 0")))
+	assert.Nil(err)
+	interrupts, err := ts.Interrupts()
+	assert.Nil(err)
+	assert.Equal(0, len(interrupts))
+
+	// Pushing something unrelated is
+	err = ts.InsertTask(common.LineID("multiple_nested:0"), common.NewTask(common.NewTreeNode("check Twitter")))
+	assert.Nil(err)
+	interrupts, err = ts.Interrupts()
+	assert.Nil(err)
+	assert.Equal(1, len(interrupts))
+	assert.Equal([]string{"task 0", "subtask 0", "subsubtask 0", "subsubsubtask 0"}, interrupts[0].Interrupted)
+	assert.Equal([]string{"check Twitter"}, interrupts[0].Interrupting)
+	assert.True(interrupts[0].End.IsZero())
+
+	// The interrupt ends when the timelog shows its node is no longer on top
+	err = ts.ArchiveLine(common.GetLineID("multiple_nested", "check Twitter"))
+	assert.Nil(err)
+	interrupts, err = ts.Interrupts()
+	assert.Nil(err)
+	assert.False(interrupts[0].End.IsZero())
+
+	// Pushing onto a non-leaf frame is an interrupt too, since the pushed node isn't a substep of the
+	// frame that was on top
+	_, err = ts.SetHoist("cli", common.GetLineID("multiple_nested", "task 0"))
+	assert.Nil(err)
+	_, err = ts.Push("cli", "multiple_nested", common.NewTask(common.NewTreeNode("text Mom")))
+	assert.Nil(err)
+	interrupts, err = ts.Interrupts()
+	assert.Nil(err)
+	assert.Equal(2, len(interrupts))
+	assert.Equal([]string{"task 0", "text Mom"}, interrupts[1].Interrupting)
+
+	// But a push that doesn't end up on top isn't
+	_, err = ts.SetHoist("cli", common.GetLineID("multiple_nested", "task 1"))
+	assert.Nil(err)
+	_, err = ts.Push("cli", "multiple_nested", common.NewTask(common.NewTreeNode("buy milk")))
+	assert.Nil(err)
+	interrupts, err = ts.Interrupts()
+	assert.Nil(err)
+	assert.Equal(2, len(interrupts))
+}
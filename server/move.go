@@ -0,0 +1,164 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// moveHistoryLines returns the lines for the history file recording that the subtree at srcPath in
+// the list identified by srcName was moved, at t, to the list identified by destName.
+//
+// A line is recorded for each side of the move:
+//
+//	2021-12-30T19:24:48 [move out make_pasta to someday] make pasta > boil water
+//	2021-12-30T19:24:48 [move in someday from make_pasta] boil water
+//
+// The elements of the path are escaped (see escapePath).
+func moveHistoryLines(srcName string, srcPath []string, destName string, t time.Time) []byte {
+	timestamp := t.Format(historyTimeFormat)
+	return []byte(fmt.Sprintf(
+		"%s [move out %s to %s] %s\n%s [move in %s from %s] %s\n",
+		timestamp,
+		srcName,
+		destName,
+		strings.Join(escapePath(srcPath), " > "),
+		timestamp,
+		destName,
+		srcName,
+		escapeReferent(srcPath[len(srcPath)-1]),
+	))
+}
+
+// findNode returns the node in taskList, the list identified by listName, whose line has the ID
+// lineId, or nil if there's no such node.
+func (ts *BasicTaskstore) findNode(listName string, taskList []*common.Task, lineId common.LineID) *common.TreeNode {
+	var found *common.TreeNode
+	for _, t := range taskList {
+		t.RootNode.Walk(func(n *common.TreeNode) error {
+			if found == nil && ts.nodeLineId(listName, n) == lineId {
+				found = n
+			}
+			return nil
+		})
+	}
+	return found
+}
+
+// MoveToList moves the node identified by lineId, along with its descendants, to the position
+// identified by dest. dest is interpreted as by InsertTask, so the subtree becomes a task in the
+// destination list.
+//
+// The source and destination lists may be the same. If they're different, both are written only if
+// neither has changed since it was read: if the destination is written but the source can't be, the
+// destination is restored, so that the subtree doesn't end up in both lists.
+//
+// If the destination list doesn't exist, it's created (in which case dest must be `<list>:0`). If
+// the source can't be written, the new list is removed again.
+func (ts *BasicTaskstore) MoveToList(lineId, dest common.LineID) error {
+	srcName, _, err := ts.splitLineId(lineId)
+	if err != nil {
+		return err
+	}
+	destName, _, err := ts.splitLineId(dest)
+	if err != nil {
+		return err
+	}
+	if !ts.isListName(srcName) {
+		return fmt.Errorf("`%s` is not a task list", srcName)
+	}
+	if !ts.isListName(destName) {
+		return fmt.Errorf("`%s` is not a task list", destName)
+	}
+
+	srcB, err := ts.readList(srcName)
+	if err != nil {
+		return err
+	}
+	srcList, err := ts.unmarshalList(srcName, srcB)
+	if err != nil {
+		return err
+	}
+	n := ts.findNode(srcName, srcList, lineId)
+	if n == nil {
+		return fmt.Errorf("no node with ID `%s`", string(lineId))
+	}
+	srcPath := nodePath(n)
+
+	// Detach the subtree from the source list.
+	if n.Parent == nil {
+		for i := range srcList {
+			if srcList[i].RootNode == n {
+				srcList = append(srcList[:i], srcList[i+1:]...)
+				break
+			}
+		}
+	} else {
+		n.Parent.RemoveChild(n)
+	}
+	task := common.NewTask(n)
+	movedId := ts.nodeLineId(destName, n)
+
+	if destName == srcName {
+		srcList, err = ts.insertIntoList(srcName, srcList, dest, task)
+		if err != nil {
+			return err
+		}
+		if err := ts.putList(srcName, srcList, StateID(srcB), "MoveToList", movedId); err != nil {
+			return err
+		}
+		ts.appendHistory(moveHistoryLines(srcName, srcPath, destName, time.Now()))
+		return nil
+	}
+
+	destB, err := ts.readList(destName)
+	// create records whether the destination list needs to be created.
+	create := false
+	if _, ok := err.(*NoSuchListError); ok {
+		create = true
+		destB = []byte{}
+	} else if err != nil {
+		return err
+	}
+	destList, err := ts.unmarshalList(destName, destB)
+	if err != nil {
+		return err
+	}
+	destList, err = ts.insertIntoList(destName, destList, dest, task)
+	if err != nil {
+		return err
+	}
+
+	newSrcB := ts.marshalList(srcList, detectIndent(srcB, ts.indent))
+	newDestB := ts.marshalList(destList, detectIndent(destB, ts.indent))
+	if create {
+		if err := ts.datastore.Create(destName); err != nil {
+			return err
+		}
+	}
+	if err := ts.datastore.CompareAndPut(destName, StateID(destB), newDestB); err != nil {
+		if create {
+			ts.datastore.Remove(destName)
+		}
+		return err
+	}
+	if err := ts.datastore.CompareAndPut(srcName, StateID(srcB), newSrcB); err != nil {
+		var rollbackErr error
+		if create {
+			rollbackErr = ts.datastore.Remove(destName)
+		} else {
+			rollbackErr = ts.datastore.CompareAndPut(destName, StateID(newDestB), destB)
+		}
+		if rollbackErr != nil {
+			return fmt.Errorf("%w (and restoring `%s` failed: %s)", err, destName, rollbackErr.Error())
+		}
+		return err
+	}
+
+	ts.appendHistory(moveHistoryLines(srcName, srcPath, destName, time.Now()))
+	ts.publish(srcName, "MoveToList", lineId, newSrcB)
+	ts.publish(destName, "MoveToList", movedId, newDestB)
+	return nil
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicTaskstore_MoveToList(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	// Move "boil water" and its subtasks to the bottom of multiple_nested
+	err := ts.MoveToList(
+		common.GetLineID("make_pasta", "\tboil water"),
+		common.GetLineID("multiple_nested", "task 1"),
+	)
+	assert.Nil(err)
+
+	taskList, err := ts.GetList("make_pasta")
+	assert.Nil(err)
+	assert.Equal(3, len(taskList[0].RootNode.Children))
+	assert.Equal("put pasta in water", taskList[0].RootNode.Children[0].Referent)
+
+	taskList, err = ts.GetList("multiple_nested")
+	assert.Nil(err)
+	assert.Equal(3, len(taskList))
+	assert.True(common.MakePasta()[0].RootNode.Children[0].Children[0].Equal(taskList[2].RootNode.Children[0]))
+	assert.Equal("boil water", taskList[2].RootNode.Referent)
+	assert.Equal(3, len(taskList[2].RootNode.Children))
+
+	b, err := ds.Get("history")
+	assert.Nil(err)
+	assert.True(regexp.MustCompile(
+		`^\S+ \[move out make_pasta to multiple_nested\] make pasta > boil water\n` +
+			`\S+ \[move in multiple_nested from make_pasta\] boil water\n$`,
+	).Match(b))
+
+	evs := ts.Feed().Since("multiple_nested", 0, 0)
+	assert.Equal(1, len(evs))
+	assert.Equal(common.GetLineID("multiple_nested", "boil water"), evs[0].LineID)
+}
+
+// Tests that moving a subtree to a list that doesn't exist creates the list.
+func TestBasicTaskstore_MoveToList_NewList(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ts, cleanup := NewBasicTaskstoreWithTestdata()
+	defer cleanup()
+
+	err := ts.MoveToList(
+		common.GetLineID("make_pasta", "\tboil water"),
+		common.LineID("someday:0"),
+	)
+	assert.Nil(err)
+	taskList, err := ts.GetList("someday")
+	assert.Nil(err)
+	if assert.Equal(1, len(taskList)) {
+		assert.Equal("boil water", taskList[0].RootNode.Referent)
+	}
+
+	// Positions other than the top don't exist in a new list, so it isn't created.
+	err = ts.MoveToList(
+		common.GetLineID("make_pasta", "\tput pasta in water"),
+		common.GetLineID("later", "some line"),
+	)
+	assert.NotNil(err)
+	_, err = ts.GetList("later")
+	assert.NotNil(err)
+}
+
+// Tests that lines can only be moved out of task lists.
+func TestBasicTaskstore_MoveToList_NotList(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	line := "2021-12-30T19:24:48 [archive make_pasta] boil water"
+	err := ds.Put("history", []byte(line+"\n"))
+	assert.Nil(err)
+
+	err = ts.MoveToList(common.GetLineID("history", line), common.LineID("make_pasta:0"))
+	assert.NotNil(err)
+	b, err := ds.Get("history")
+	assert.Nil(err)
+	assert.Equal(line+"\n", string(b))
+}
+
+func TestBasicTaskstore_MoveToList_SameList(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ts, cleanup := NewBasicTaskstoreWithTestdata()
+	defer cleanup()
+
+	err := ts.MoveToList(
+		common.GetLineID("multiple_nested", "\t\tsubsubtask 0"),
+		common.LineID("multiple_nested:0"),
+	)
+	assert.Nil(err)
+	taskList, err := ts.GetList("multiple_nested")
+	assert.Nil(err)
+	assert.Equal(3, len(taskList))
+	assert.Equal("subsubtask 0", taskList[0].RootNode.Referent)
+	assert.Equal(0, len(taskList[1].RootNode.Children[0].Children))
+
+	// A subtree can't be moved under itself
+	err = ts.MoveToList(
+		common.GetLineID("multiple_nested", "task 0"),
+		common.GetLineID("multiple_nested", "task 0"),
+	)
+	assert.NotNil(err)
+}
+
+// refusingDatastore is a Datastore that refuses, with ErrStaleWrite, to CompareAndPut the file
+// with the given name.
+type refusingDatastore struct {
+	Datastore
+	name string
+}
+
+func (ds *refusingDatastore) CompareAndPut(name, stateId string, b []byte) error {
+	if name == ds.name {
+		return ErrStaleWrite
+	}
+	return ds.Datastore.CompareAndPut(name, stateId, b)
+}
+
+// Tests that if the source list can't be written, the destination list is restored.
+func TestBasicTaskstore_MoveToList_Rollback(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	fsds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(&refusingDatastore{Datastore: fsds, name: "make_pasta"})
+
+	before, err := fsds.Get("multiple_nested")
+	assert.Nil(err)
+	err = ts.MoveToList(
+		common.GetLineID("make_pasta", "\tboil water"),
+		common.LineID("multiple_nested:0"),
+	)
+	assert.Equal(ErrStaleWrite, err)
+
+	b, err := fsds.Get("multiple_nested")
+	assert.Nil(err)
+	assert.Equal(string(before), string(b))
+	_, err = fsds.Get("history")
+	assert.NotNil(err)
+	assert.Equal(0, len(ts.Feed().Since("multiple_nested", 0, 0)))
+
+	// A list created for the move is removed again
+	err = ts.MoveToList(
+		common.GetLineID("make_pasta", "\tboil water"),
+		common.LineID("someday:0"),
+	)
+	assert.Equal(ErrStaleWrite, err)
+	_, err = fsds.Get("someday")
+	assert.NotNil(err)
+}
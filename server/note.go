@@ -0,0 +1,106 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// isNoteLine determines whether line, a line of basic-format data, is part of a node's note: that
+// is, whether its text (after any indentation) is `|` or begins with `| `.
+func isNoteLine(line string) bool {
+	text := strings.TrimLeft(line, " \t")
+	return text == "|" || strings.HasPrefix(text, "| ")
+}
+
+// noteText returns the text of the note line line, without its indentation and `|` marker.
+func noteText(line string) string {
+	text := strings.TrimPrefix(strings.TrimLeft(line, " \t"), "|")
+	return strings.TrimPrefix(text, " ")
+}
+
+// noteLines returns the basic-format lines that represent note, each prefixed with indentation.
+func noteLines(note, indentation string) []string {
+	if note == "" {
+		return []string{}
+	}
+	rslt := make([]string, 0)
+	for _, l := range strings.Split(note, "\n") {
+		if l == "" {
+			rslt = append(rslt, indentation+"|")
+		} else {
+			rslt = append(rslt, indentation+"| "+l)
+		}
+	}
+	return rslt
+}
+
+// readNote returns the note attached to line lineNo of lines, the lines of a list's basic-format
+// data, along with the number of the first line of the note. If the line has no note, the empty
+// string and lineNo are returned.
+func readNote(lines [][]byte, lineNo int) (int, string) {
+	start := lineNo
+	for start > 0 && isNoteLine(string(lines[start-1])) {
+		start--
+	}
+	texts := make([]string, 0, lineNo-start)
+	for _, line := range lines[start:lineNo] {
+		texts = append(texts, noteText(string(line)))
+	}
+	return start, strings.Join(texts, "\n")
+}
+
+// locateNode is like locateLine, but it's an error if the line identified by lineId isn't a node.
+func (ts *BasicTaskstore) locateNode(lineId common.LineID) (string, []byte, [][]byte, int, error) {
+	listName, b, lineNo, err := ts.locateLine(lineId)
+	if err != nil {
+		return "", nil, nil, 0, err
+	}
+	lines := bytes.Split(b, []byte("\n"))
+	if s := string(lines[lineNo]); isCommentLine(s) || isNoteLine(s) {
+		return "", nil, nil, 0, fmt.Errorf("line `%s` is not a node", string(lineId))
+	}
+	return listName, b, lines, lineNo, nil
+}
+
+// GetNote returns the note attached to the node identified by lineId. If the node has no note, the
+// empty string is returned.
+func (ts *BasicTaskstore) GetNote(lineId common.LineID) (string, error) {
+	_, _, lines, lineNo, err := ts.locateNode(lineId)
+	if err != nil {
+		return "", err
+	}
+	_, note := readNote(lines, lineNo)
+	return note, nil
+}
+
+// SetNote replaces the note attached to the node identified by lineId with note. If note is empty,
+// the node's note is removed. Trailing newlines are dropped.
+//
+// The node's line is left as it is, so its ID doesn't change.
+func (ts *BasicTaskstore) SetNote(lineId common.LineID, note string) error {
+	listName, b, lines, lineNo, err := ts.locateNode(lineId)
+	if err != nil {
+		return err
+	}
+	baseStateId := StateID(b)
+
+	unit := detectIndent(b, ts.indent)
+	indent, _ := ts.parseLine(lines[lineNo], unit)
+	start, _ := readNote(lines, lineNo)
+	newLines := make([][]byte, 0, len(lines))
+	newLines = append(newLines, lines[:start]...)
+	for _, l := range noteLines(strings.TrimRight(note, "\n"), strings.Repeat(unit, indent)) {
+		newLines = append(newLines, []byte(l))
+	}
+	newLines = append(newLines, lines[lineNo:]...)
+	b = bytes.Join(newLines, []byte("\n"))
+
+	if err := ts.datastore.CompareAndPut(listName, baseStateId, b); err != nil {
+		return err
+	}
+	ts.publish(listName, "SetNote", lineId, b)
+	return nil
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicTaskstore_SetNote(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	lineId := common.GetLineID("make_pasta", "\tboil water")
+	err := ts.SetNote(lineId, "plumber: 555-0100\n\nask for Sam")
+	assert.Nil(err)
+	b, err := ds.Get("make_pasta")
+	assert.Nil(err)
+	assert.Contains(string(b), "\t\tturn burner on\n\t| plumber: 555-0100\n\t|\n\t| ask for Sam\n\tboil water\n")
+
+	note, err := ts.GetNote(lineId)
+	assert.Nil(err)
+	assert.Equal("plumber: 555-0100\n\nask for Sam", note)
+
+	taskList, err := ts.GetList("make_pasta")
+	assert.Nil(err)
+	boilWater := taskList[0].RootNode.Children[0]
+	assert.Equal("plumber: 555-0100\n\nask for Sam", boilWater.Note)
+	assert.Equal(3, len(boilWater.Children))
+
+	// The note survives a round trip
+	err = ts.PutList("make_pasta", taskList)
+	assert.Nil(err)
+	b2, err := ds.Get("make_pasta")
+	assert.Nil(err)
+	assert.Equal(string(b), string(b2))
+
+	// Replacing and removing
+	err = ts.SetNote(lineId, "just salt it")
+	assert.Nil(err)
+	note, err = ts.GetNote(lineId)
+	assert.Nil(err)
+	assert.Equal("just salt it", note)
+	err = ts.SetNote(lineId, "")
+	assert.Nil(err)
+	b, err = ds.Get("make_pasta")
+	assert.Nil(err)
+	assert.NotContains(string(b), "|")
+}
+
+func TestBasicTaskstore_ArchiveLine_Note(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	lineId := common.GetLineID("make_pasta", "\tboil water")
+	err := ts.SetNote(lineId, "salt it")
+	assert.Nil(err)
+	err = ts.ArchiveLine(lineId)
+	assert.Nil(err)
+
+	b, err := ds.Get("make_pasta")
+	assert.Nil(err)
+	assert.NotContains(string(b), "salt it")
+	taskList, err := ts.GetList("make_pasta")
+	assert.Nil(err)
+	assert.Equal("", taskList[0].RootNode.Children[2].Note)
+}
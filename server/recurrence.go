@@ -0,0 +1,334 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// recurrencesName is the name of the Datastore file in which recurrences are stored.
+const recurrencesName = "recurrences"
+
+// Recurrence is a task that's added to a list over and over, according to a schedule.
+//
+// Each time the recurrence comes due, a fresh copy of Template is inserted at the top of the list.
+// If the copy from last time is still in the list, though, no new copy is inserted: there's no
+// sense in having two of the same routine on the stack.
+type Recurrence struct {
+	// ID identifies the recurrence among all the recurrences in the Datastore.
+	ID       string
+	ListName string
+	// Schedule is the spec of the recurrence's schedule (see common.Schedule).
+	Schedule string
+	Paused   bool
+	// Last is the time as of which the recurrence's occurrences have been dealt with. The next
+	// copy of Template is due at the first time in the schedule after Last.
+	Last     time.Time
+	Template *common.Task
+}
+
+// Next returns the time at which rec next comes due.
+func (rec *Recurrence) Next() (time.Time, error) {
+	sched, err := common.ParseSchedule(rec.Schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return sched.Next(rec.Last.In(time.Local)), nil
+}
+
+// marshalRecurrences returns the contents of the recurrences file for recs.
+//
+// Each recurrence is represented by a header line consisting of tab-separated fields (ID, list
+// name, `active` or `paused`, Last in RFC 3339 format, and schedule), followed by the template in
+// the basic format, with every line indented by an extra tab. For example:
+//
+//	1	morning	active	2026-10-19T07:00:00-04:00	every weekday 7:00
+//			make coffee
+//		morning routine
+func (ts *BasicTaskstore) marshalRecurrences(recs []*Recurrence) []byte {
+	b := []byte{}
+	for _, rec := range recs {
+		status := "active"
+		if rec.Paused {
+			status = "paused"
+		}
+		fields := []string{rec.ID, rec.ListName, status, rec.Last.Format(time.RFC3339), rec.Schedule}
+		b = append(b, []byte(strings.Join(fields, "\t")+"\n")...)
+
+		tmpl := ts.marshalList([]*common.Task{rec.Template}, IndentTab)
+		for _, line := range bytes.SplitAfter(tmpl, []byte("\n")) {
+			if len(line) > 0 {
+				b = append(b, '\t')
+				b = append(b, line...)
+			}
+		}
+	}
+	return b
+}
+
+// unmarshalRecurrences parses the contents of the recurrences file.
+func (ts *BasicTaskstore) unmarshalRecurrences(b []byte) ([]*Recurrence, error) {
+	recs := make([]*Recurrence, 0)
+	// tmpls holds the basic-format template data for each recurrence in recs.
+	tmpls := make([][]byte, 0)
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] == '\t' {
+			if len(recs) == 0 {
+				return nil, fmt.Errorf("recurrence template line `%s` has no header", string(line))
+			}
+			tmpls[len(tmpls)-1] = append(tmpls[len(tmpls)-1], append(line[1:], '\n')...)
+			continue
+		}
+
+		fields := strings.Split(string(line), "\t")
+		if len(fields) != 5 || (fields[2] != "active" && fields[2] != "paused") {
+			return nil, fmt.Errorf("malformed recurrence line `%s`", string(line))
+		}
+		last, err := time.Parse(time.RFC3339, fields[3])
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, &Recurrence{
+			ID:       fields[0],
+			ListName: fields[1],
+			Paused:   fields[2] == "paused",
+			Last:     last,
+			Schedule: fields[4],
+		})
+		tmpls = append(tmpls, []byte{})
+	}
+
+	for i, rec := range recs {
+		taskList, err := ts.unmarshalList("recurrence "+rec.ID, tmpls[i])
+		if err != nil {
+			return nil, err
+		}
+		if len(taskList) != 1 {
+			return nil, fmt.Errorf("recurrence %s has %d template tasks; expected 1", rec.ID, len(taskList))
+		}
+		rec.Template = taskList[0]
+	}
+	return recs, nil
+}
+
+// readRecurrences returns all the recurrences in the Datastore.
+//
+// The caller must hold ts.recurMu.
+func (ts *BasicTaskstore) readRecurrences() ([]*Recurrence, error) {
+	b, err := ts.datastore.Get(recurrencesName)
+	if err != nil {
+		// No recurrences file yet
+		return []*Recurrence{}, nil
+	}
+	return ts.unmarshalRecurrences(b)
+}
+
+// writeRecurrences replaces all the recurrences in the Datastore with recs.
+//
+// The caller must hold ts.recurMu.
+func (ts *BasicTaskstore) writeRecurrences(recs []*Recurrence) error {
+	return ts.datastore.Put(recurrencesName, ts.marshalRecurrences(recs))
+}
+
+// AddRecurrence adds a recurrence that inserts template into the list identified by listName
+// according to the schedule described by spec (see common.Schedule).
+//
+// The first copy of template is inserted the next time the schedule comes due, not right away.
+func (ts *BasicTaskstore) AddRecurrence(listName, spec string, template *common.Task) (*Recurrence, error) {
+	if _, err := common.ParseSchedule(spec); err != nil {
+		return nil, err
+	}
+	if !ts.isListName(listName) {
+		return nil, fmt.Errorf("`%s` is not a task list", listName)
+	}
+	if _, err := ts.readList(listName); err != nil {
+		return nil, err
+	}
+
+	ts.recurMu.Lock()
+	defer ts.recurMu.Unlock()
+
+	recs, err := ts.readRecurrences()
+	if err != nil {
+		return nil, err
+	}
+	// IDs are assigned sequentially, so that they're easy to type.
+	maxId := 0
+	for _, rec := range recs {
+		if n, err := strconv.Atoi(rec.ID); err == nil && n > maxId {
+			maxId = n
+		}
+	}
+
+	rec := &Recurrence{
+		ID:       strconv.Itoa(maxId + 1),
+		ListName: listName,
+		Schedule: spec,
+		Last:     time.Now().Truncate(time.Second),
+		Template: template,
+	}
+	if err := ts.writeRecurrences(append(recs, rec)); err != nil {
+		return nil, err
+	}
+	ts.feed.Publish(ChangeEvent{ListName: listName, Op: "AddRecurrence"})
+	return rec, nil
+}
+
+// GetRecurrences returns the recurrences for the list identified by listName. If listName is
+// empty, the recurrences for all lists are returned.
+func (ts *BasicTaskstore) GetRecurrences(listName string) ([]*Recurrence, error) {
+	ts.recurMu.Lock()
+	defer ts.recurMu.Unlock()
+
+	recs, err := ts.readRecurrences()
+	if err != nil {
+		return nil, err
+	}
+	rslt := make([]*Recurrence, 0, len(recs))
+	for _, rec := range recs {
+		if listName == "" || rec.ListName == listName {
+			rslt = append(rslt, rec)
+		}
+	}
+	return rslt, nil
+}
+
+// SetRecurrencePaused pauses or resumes the recurrence identified by id.
+//
+// A paused recurrence inserts nothing into its list. When it's resumed, occurrences that were
+// missed while it was paused are skipped.
+func (ts *BasicTaskstore) SetRecurrencePaused(id string, paused bool) error {
+	ts.recurMu.Lock()
+	defer ts.recurMu.Unlock()
+
+	recs, err := ts.readRecurrences()
+	if err != nil {
+		return err
+	}
+	for _, rec := range recs {
+		if rec.ID != id {
+			continue
+		}
+		if rec.Paused && !paused {
+			rec.Last = time.Now().Truncate(time.Second)
+		}
+		rec.Paused = paused
+		if err := ts.writeRecurrences(recs); err != nil {
+			return err
+		}
+		// Publish so that Schedule takes note of the change.
+		ts.feed.Publish(ChangeEvent{ListName: rec.ListName, Op: "SetRecurrencePaused"})
+		return nil
+	}
+	return fmt.Errorf("no recurrence with ID `%s`", id)
+}
+
+// DeleteRecurrence deletes the recurrence identified by id.
+//
+// Copies of the recurrence's template that are already in the list are left alone.
+func (ts *BasicTaskstore) DeleteRecurrence(id string) error {
+	ts.recurMu.Lock()
+	defer ts.recurMu.Unlock()
+
+	recs, err := ts.readRecurrences()
+	if err != nil {
+		return err
+	}
+	for i, rec := range recs {
+		if rec.ID != id {
+			continue
+		}
+		if err := ts.writeRecurrences(append(recs[:i], recs[i+1:]...)); err != nil {
+			return err
+		}
+		ts.feed.Publish(ChangeEvent{ListName: rec.ListName, Op: "DeleteRecurrence"})
+		return nil
+	}
+	return fmt.Errorf("no recurrence with ID `%s`", id)
+}
+
+// instantiate inserts a copy of rec's template at the top of rec's list, unless the previous copy
+// is still open (that is, there's already a task in the list with the same referent).
+func (ts *BasicTaskstore) instantiate(rec *Recurrence) error {
+	b, err := ts.datastore.Get(rec.ListName)
+	if err != nil {
+		return err
+	}
+	taskList, err := ts.unmarshalList(rec.ListName, b)
+	if err != nil {
+		return err
+	}
+	for _, t := range taskList {
+		if t.RootNode.Referent == rec.Template.RootNode.Referent {
+			return nil
+		}
+	}
+
+	// Copy the template by round-tripping it through the basic format, so that the list and the
+	// template don't end up sharing nodes.
+	copied, err := ts.unmarshalList(rec.ListName, ts.marshalList([]*common.Task{rec.Template}, IndentTab))
+	if err != nil {
+		return err
+	}
+	insertedId := ts.nodeLineId(rec.ListName, rec.Template.RootNode)
+	return ts.putList(rec.ListName, append(copied, taskList...), StateID(b), "Recur", insertedId)
+}
+
+// recur instantiates each active recurrence that has come due as of now.
+//
+// recur returns the earliest time after now at which an active recurrence comes due, or the zero
+// time if there is none.
+func (ts *BasicTaskstore) recur(now time.Time) (time.Time, error) {
+	var next time.Time
+
+	ts.recurMu.Lock()
+	defer ts.recurMu.Unlock()
+
+	recs, err := ts.readRecurrences()
+	if err != nil {
+		return next, err
+	}
+
+	changed := false
+	for _, rec := range recs {
+		if rec.Paused {
+			continue
+		}
+		due, err := rec.Next()
+		if err != nil || due.IsZero() {
+			continue
+		}
+		if due.After(now) {
+			if next.IsZero() || due.Before(next) {
+				next = due
+			}
+			continue
+		}
+
+		// If the list can't be written right now (e.g. because it's malformed, or because it's
+		// changed out from under us), we'll try again next time.
+		if err := ts.instantiate(rec); err != nil {
+			continue
+		}
+		// However many occurrences were missed, only one copy is inserted.
+		rec.Last = now.Truncate(time.Second)
+		changed = true
+		if due, err := rec.Next(); err == nil && !due.IsZero() && (next.IsZero() || due.Before(next)) {
+			next = due
+		}
+	}
+
+	if changed {
+		if err := ts.writeRecurrences(recs); err != nil {
+			return next, err
+		}
+	}
+	return next, nil
+}
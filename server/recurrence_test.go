@@ -0,0 +1,164 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// morningRoutine returns a template task for use in recurrence tests.
+func morningRoutine() *common.Task {
+	n := common.NewTreeNode("morning routine")
+	n.AddChild(common.NewTreeNode("make coffee"))
+	return common.NewTask(n)
+}
+
+func TestBasicTaskstore_marshalRecurrences(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ts := NewBasicTaskstore(nil)
+	last := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+	recs := []*Recurrence{
+		&Recurrence{
+			ID:       "1",
+			ListName: "morning",
+			Schedule: "every weekday 7:00",
+			Last:     last,
+			Template: morningRoutine(),
+		},
+		&Recurrence{
+			ID:       "3",
+			ListName: "chores",
+			Schedule: "0 9 * * 6",
+			Paused:   true,
+			Last:     last,
+			Template: common.NewTask(common.NewTreeNode("mow lawn")),
+		},
+	}
+
+	b := ts.marshalRecurrences(recs)
+	assert.Equal(
+		"1\tmorning\tactive\t2026-10-19T07:00:00Z\tevery weekday 7:00\n"+
+			"\t\tmake coffee\n"+
+			"\tmorning routine\n"+
+			"3\tchores\tpaused\t2026-10-19T07:00:00Z\t0 9 * * 6\n"+
+			"\tmow lawn\n",
+		string(b),
+	)
+
+	got, err := ts.unmarshalRecurrences(b)
+	assert.Nil(err)
+	assert.Equal(2, len(got))
+	for i := range recs {
+		assert.Equal(recs[i].ID, got[i].ID)
+		assert.Equal(recs[i].ListName, got[i].ListName)
+		assert.Equal(recs[i].Schedule, got[i].Schedule)
+		assert.Equal(recs[i].Paused, got[i].Paused)
+		assert.True(recs[i].Last.Equal(got[i].Last))
+		assert.True(recs[i].Template.Equal(got[i].Template))
+	}
+
+	for _, s := range []string{
+		"\tmow lawn\n",
+		"1\tchores\tsleeping\t2026-10-19T07:00:00Z\t0 9 * * 6\n\tmow lawn\n",
+		"1\tchores\tactive\t2026-10-19T07:00:00Z\t0 9 * * 6\n\tmow lawn\n\tmake coffee\n",
+	} {
+		_, err := ts.unmarshalRecurrences([]byte(s))
+		assert.NotNil(err, s)
+	}
+}
+
+// Tests that recurrences are instantiated when due, but not while the previous copy is open or
+// while they're paused.
+func TestBasicTaskstore_recur(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	rec, err := ts.AddRecurrence("make_pasta", "* * * * *", morningRoutine())
+	assert.Nil(err)
+	assert.Equal("1", rec.ID)
+	_, err = ts.AddRecurrence("make_pasta", "every someday", morningRoutine())
+	assert.NotNil(err)
+	_, err = ts.AddRecurrence("history", "* * * * *", morningRoutine())
+	assert.NotNil(err)
+
+	taskList, err := ts.GetList("make_pasta")
+	assert.Nil(err)
+	nTasks := len(taskList)
+
+	// Not due yet
+	now := time.Now()
+	next, err := ts.recur(now)
+	assert.Nil(err)
+	assert.True(next.After(now))
+	taskList, err = ts.GetList("make_pasta")
+	assert.Nil(err)
+	assert.Equal(nTasks, len(taskList))
+
+	// Due; a copy goes on top
+	now = now.Add(2 * time.Minute)
+	_, err = ts.recur(now)
+	assert.Nil(err)
+	taskList, err = ts.GetList("make_pasta")
+	assert.Nil(err)
+	assert.Equal(nTasks+1, len(taskList))
+	assert.True(morningRoutine().Equal(taskList[0]))
+	assert.Equal("make coffee", common.Top(taskList).Referent)
+
+	// Due again, but the previous copy is still open
+	now = now.Add(2 * time.Minute)
+	_, err = ts.recur(now)
+	assert.Nil(err)
+	taskList, err = ts.GetList("make_pasta")
+	assert.Nil(err)
+	assert.Equal(nTasks+1, len(taskList))
+
+	// Once the previous copy is done, the next one can go in
+	err = ts.PutList("make_pasta", taskList[1:])
+	assert.Nil(err)
+	now = now.Add(2 * time.Minute)
+	_, err = ts.recur(now)
+	assert.Nil(err)
+	taskList, err = ts.GetList("make_pasta")
+	assert.Nil(err)
+	assert.Equal(nTasks+1, len(taskList))
+	assert.True(morningRoutine().Equal(taskList[0]))
+
+	// Paused recurrences do nothing
+	err = ts.PutList("make_pasta", taskList[1:])
+	assert.Nil(err)
+	err = ts.SetRecurrencePaused("1", true)
+	assert.Nil(err)
+	now = now.Add(2 * time.Minute)
+	next, err = ts.recur(now)
+	assert.Nil(err)
+	assert.True(next.IsZero())
+	taskList, err = ts.GetList("make_pasta")
+	assert.Nil(err)
+	assert.Equal(nTasks, len(taskList))
+
+	recs, err := ts.GetRecurrences("make_pasta")
+	assert.Nil(err)
+	assert.Equal(1, len(recs))
+	assert.True(recs[0].Paused)
+	recs, err = ts.GetRecurrences("multiple_nested")
+	assert.Nil(err)
+	assert.Equal(0, len(recs))
+
+	err = ts.DeleteRecurrence("1")
+	assert.Nil(err)
+	err = ts.DeleteRecurrence("1")
+	assert.NotNil(err)
+	err = ts.SetRecurrencePaused("1", false)
+	assert.NotNil(err)
+	recs, err = ts.GetRecurrences("")
+	assert.Nil(err)
+	assert.Equal(0, len(recs))
+}
@@ -0,0 +1,49 @@
+package server
+
+type GetActiveListRequest struct{}
+
+type GetActiveListResponse struct {
+	Response
+	// ListName is the name of the active list, or empty if there is none.
+	ListName string
+}
+
+// GetActiveList returns the name of the active list.
+func (s *Server) GetActiveList(req *GetActiveListRequest, resp *GetActiveListResponse) error {
+	name, err := s.taskstore.GetActiveList()
+	if err != nil {
+		return apiError(err)
+	}
+	resp.ListName = name
+	return nil
+}
+
+type SetActiveListRequest struct {
+	ListName string
+}
+
+type SetActiveListResponse struct {
+	Response
+}
+
+// SetActiveList makes the list identified by req.ListName the active list.
+func (s *Server) SetActiveList(req *SetActiveListRequest, resp *SetActiveListResponse) error {
+	return apiError(s.taskstore.SetActiveList(req.ListName))
+}
+
+type GetStacksRequest struct{}
+
+type GetStacksResponse struct {
+	Response
+	Stacks []Stack
+}
+
+// GetStacks returns a summary of each task list, including its top frame.
+func (s *Server) GetStacks(req *GetStacksRequest, resp *GetStacksResponse) error {
+	stacks, err := s.taskstore.Stacks()
+	if err != nil {
+		return apiError(err)
+	}
+	resp.Stacks = stacks
+	return nil
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActiveList(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	err := s.SetActiveList(&SetActiveListRequest{ListName: "make_pasta"}, new(SetActiveListResponse))
+	assert.Nil(err)
+	getResp := new(GetActiveListResponse)
+	err = s.GetActiveList(&GetActiveListRequest{}, getResp)
+	assert.Nil(err)
+	assert.Equal("make_pasta", getResp.ListName)
+
+	stacksResp := new(GetStacksResponse)
+	err = s.GetStacks(&GetStacksRequest{}, stacksResp)
+	assert.Nil(err)
+	for _, st := range stacksResp.Stacks {
+		assert.Equal(st.ListName == "make_pasta", st.Active)
+	}
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"github.com/danslimmon/impulse/common"
+)
+
+type ArchiveLineRequest struct {
+	LineID common.LineID
+}
+
+type ArchiveLineResponse struct {
+	Response
+}
+
+// ArchiveLine archives the line identified by req.LineID. Its subtasks, if any, take its place.
+func (s *Server) ArchiveLine(req *ArchiveLineRequest, resp *ArchiveLineResponse) error {
+	return apiError(s.taskstore.ArchiveLine(req.LineID))
+}
+
+type ArchiveSubtreeRequest struct {
+	LineID common.LineID
+}
+
+type ArchiveSubtreeResponse struct {
+	Response
+}
+
+// ArchiveSubtree archives the line identified by req.LineID along with all its descendants.
+func (s *Server) ArchiveSubtree(req *ArchiveSubtreeRequest, resp *ArchiveSubtreeResponse) error {
+	return apiError(s.taskstore.ArchiveSubtree(req.LineID))
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveLine(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	apiReq := &ArchiveLineRequest{LineID: common.GetLineID("make_pasta", "\t\tput water in pot")}
+	err := s.ArchiveLine(apiReq, new(ArchiveLineResponse))
+	assert.Nil(err)
+
+	taskList, err := s.taskstore.GetList("make_pasta")
+	assert.Nil(err)
+	assert.Equal("put pot on burner", common.Top(taskList).Referent)
+
+	// The line is gone
+	err = s.ArchiveLine(apiReq, new(ArchiveLineResponse))
+	assert.NotNil(err)
+}
+
+func TestArchiveSubtree(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	apiReq := &ArchiveSubtreeRequest{LineID: common.GetLineID("make_pasta", "\tboil water")}
+	err := s.ArchiveSubtree(apiReq, new(ArchiveSubtreeResponse))
+	assert.Nil(err)
+
+	taskList, err := s.taskstore.GetList("make_pasta")
+	assert.Nil(err)
+	assert.Equal("put pasta in water", common.Top(taskList).Referent)
+}
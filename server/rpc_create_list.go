@@ -0,0 +1,14 @@
+package server
+
+type CreateListRequest struct {
+	ListName string
+}
+
+type CreateListResponse struct {
+	Response
+}
+
+// CreateList creates a new, empty list called req.ListName.
+func (s *Server) CreateList(req *CreateListRequest, resp *CreateListResponse) error {
+	return apiError(s.taskstore.CreateList(req.ListName))
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateList(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	apiReq := &CreateListRequest{ListName: "groceries"}
+	apiResp := new(CreateListResponse)
+	err := s.CreateList(apiReq, apiResp)
+	assert.Nil(err)
+
+	taskList, err := s.taskstore.GetList("groceries")
+	assert.Nil(err)
+	assert.Equal(0, len(taskList))
+
+	// The list already exists
+	err = s.CreateList(apiReq, apiResp)
+	assert.NotNil(err)
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"github.com/danslimmon/impulse/exporter"
+)
+
+type ExportRequest struct {
+	ListName string
+	// Format is the name of the format to export to (see exporter.Formats).
+	Format string
+}
+
+type ExportResponse struct {
+	Response
+	Data string
+}
+
+// Export returns the representation of the list identified by req.ListName in req.Format.
+func (s *Server) Export(req *ExportRequest, resp *ExportResponse) error {
+	taskList, err := s.taskstore.GetList(req.ListName)
+	if err != nil {
+		return apiError(err)
+	}
+	b, err := exporter.Export(req.Format, req.ListName, taskList)
+	if err != nil {
+		return apiError(err)
+	}
+	resp.Data = string(b)
+	return nil
+}
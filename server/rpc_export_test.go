@@ -0,0 +1,26 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	resp := new(ExportResponse)
+	err := s.Export(&ExportRequest{ListName: "make_pasta", Format: "markdown"}, resp)
+	assert.Nil(err)
+	assert.True(strings.HasPrefix(resp.Data, "# make_pasta\n\n- [ ] make pasta\n  - [ ] boil water\n"))
+
+	err = s.Export(&ExportRequest{ListName: "make_pasta", Format: "pdf"}, new(ExportResponse))
+	assert.NotNil(err)
+	err = s.Export(&ExportRequest{ListName: "nonexistent", Format: "markdown"}, new(ExportResponse))
+	assert.NotNil(err)
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"time"
+)
+
+type StartFocusRequest struct {
+	ListName string
+	Duration time.Duration
+}
+
+type StartFocusResponse struct {
+	Response
+	Session *FocusSession
+}
+
+// StartFocus starts a focus session of length req.Duration on the top frame of the list identified
+// by req.ListName.
+func (s *Server) StartFocus(req *StartFocusRequest, resp *StartFocusResponse) error {
+	fs, err := s.taskstore.StartFocus(req.ListName, req.Duration)
+	if err != nil {
+		return apiError(err)
+	}
+	resp.Session = fs
+	return nil
+}
+
+type GetFocusRequest struct{}
+
+type GetFocusResponse struct {
+	Response
+	// Session is the focus session in progress, or nil if there is none.
+	Session *FocusSession
+	// Remaining is the time left in Session.
+	Remaining time.Duration
+}
+
+// GetFocus returns the focus session in progress, if any, along with the time remaining in it.
+func (s *Server) GetFocus(req *GetFocusRequest, resp *GetFocusResponse) error {
+	fs, err := s.taskstore.GetFocus()
+	if err != nil {
+		return apiError(err)
+	}
+	resp.Session = fs
+	if fs != nil {
+		resp.Remaining = fs.Remaining(time.Now())
+	}
+	return nil
+}
+
+type StopFocusRequest struct{}
+
+type StopFocusResponse struct {
+	Response
+}
+
+// StopFocus abandons the focus session in progress.
+func (s *Server) StopFocus(req *StopFocusRequest, resp *StopFocusResponse) error {
+	return apiError(s.taskstore.StopFocus())
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFocus(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	// No session yet
+	getResp := new(GetFocusResponse)
+	err := s.GetFocus(new(GetFocusRequest), getResp)
+	assert.Nil(err)
+	assert.Nil(getResp.Session)
+
+	startReq := &StartFocusRequest{ListName: "make_pasta", Duration: 25 * time.Minute}
+	startResp := new(StartFocusResponse)
+	err = s.StartFocus(startReq, startResp)
+	assert.Nil(err)
+	assert.Equal([]string{"make pasta", "boil water", "put water in pot"}, startResp.Session.Path)
+
+	// Can't start a second session
+	err = s.StartFocus(startReq, startResp)
+	assert.NotNil(err)
+
+	getResp = new(GetFocusResponse)
+	err = s.GetFocus(new(GetFocusRequest), getResp)
+	assert.Nil(err)
+	assert.Equal("make_pasta", getResp.Session.ListName)
+	assert.True(getResp.Remaining > 24*time.Minute)
+
+	err = s.StopFocus(new(StopFocusRequest), new(StopFocusResponse))
+	assert.Nil(err)
+
+	getResp = new(GetFocusResponse)
+	err = s.GetFocus(new(GetFocusRequest), getResp)
+	assert.Nil(err)
+	assert.Nil(getResp.Session)
+
+	// Nothing to stop
+	err = s.StopFocus(new(StopFocusRequest), new(StopFocusResponse))
+	assert.NotNil(err)
+}
@@ -0,0 +1,38 @@
+package server
+
+type CheckListRequest struct {
+	ListName string
+}
+
+type CheckListResponse struct {
+	Response
+	Result *CheckResult
+}
+
+// CheckList reports every problem with the data of the list identified by req.ListName, along with
+// the repairs that RepairList would make.
+func (s *Server) CheckList(req *CheckListRequest, resp *CheckListResponse) error {
+	rslt, err := s.taskstore.CheckList(req.ListName)
+	if err != nil {
+		return apiError(err)
+	}
+	resp.Result = rslt
+	resp.StateID = rslt.StateID
+	return nil
+}
+
+type RepairListRequest struct {
+	ListName string
+	// StateID is the StateID of the list as checked by CheckList.
+	StateID string
+}
+
+type RepairListResponse struct {
+	Response
+}
+
+// RepairList fixes the repairable problems in the list identified by req.ListName, provided it
+// hasn't changed since req.StateID.
+func (s *Server) RepairList(req *RepairListRequest, resp *RepairListResponse) error {
+	return apiError(s.taskstore.RepairList(req.ListName, req.StateID))
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckList(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	checkResp := new(CheckListResponse)
+	err := s.CheckList(&CheckListRequest{ListName: "malformed/no_trailing_newline"}, checkResp)
+	assert.Nil(err)
+	assert.Equal(3, checkResp.Result.Problems[0].Line)
+
+	repairReq := &RepairListRequest{ListName: "malformed/no_trailing_newline", StateID: checkResp.StateID}
+	err = s.RepairList(repairReq, new(RepairListResponse))
+	assert.Nil(err)
+
+	checkResp = new(CheckListResponse)
+	err = s.CheckList(&CheckListRequest{ListName: "malformed/no_trailing_newline"}, checkResp)
+	assert.Nil(err)
+	assert.Equal(0, len(checkResp.Result.Problems))
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"time"
+
+	"github.com/danslimmon/impulse/analytics"
+)
+
+type GetInterruptReportRequest struct{}
+
+type GetInterruptReportResponse struct {
+	Response
+	// Interrupts are all the recorded interrupts, in chronological order, and Summaries summarize
+	// them by the frame interrupted (see analytics.SummarizeInterrupts).
+	Interrupts []analytics.Interrupt
+	Summaries  []analytics.InterruptSummary
+}
+
+// GetInterruptReport reports how often, for how long, and by what each frame has been interrupted.
+func (s *Server) GetInterruptReport(req *GetInterruptReportRequest, resp *GetInterruptReportResponse) error {
+	interrupts, err := s.taskstore.Interrupts()
+	if err != nil {
+		return apiError(err)
+	}
+	resp.Interrupts = interrupts
+	resp.Summaries = analytics.SummarizeInterrupts(interrupts, time.Now())
+	return nil
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetInterruptReport(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	err := s.taskstore.InsertTask(common.LineID("make_pasta:0"), common.NewTask(common.NewTreeNode("check Twitter #social")))
+	assert.Nil(err)
+
+	apiResp := new(GetInterruptReportResponse)
+	err = s.GetInterruptReport(&GetInterruptReportRequest{}, apiResp)
+	assert.Nil(err)
+	assert.Equal(1, len(apiResp.Interrupts))
+	assert.Equal(1, len(apiResp.Summaries))
+	assert.Equal([]string{"make pasta", "boil water", "put water in pot"}, apiResp.Summaries[0].Frame)
+	assert.Equal("#social", apiResp.Summaries[0].Kinds[0].Kind)
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/danslimmon/impulse/analytics"
+)
+
+type GetStatsRequest struct {
+	// Days is the number of days, up to and including today, that the statistics cover.
+	Days int
+}
+
+type GetStatsResponse struct {
+	Response
+	Stats *analytics.Stats
+}
+
+// GetStats computes productivity statistics for the last req.Days days. See BasicTaskstore.Stats.
+func (s *Server) GetStats(req *GetStatsRequest, resp *GetStatsResponse) error {
+	if req.Days < 1 {
+		return apiError(fmt.Errorf("number of days must be positive"))
+	}
+	stats, err := s.taskstore.Stats(time.Now().AddDate(0, 0, 1-req.Days))
+	if err != nil {
+		return apiError(err)
+	}
+	resp.Stats = stats
+	return nil
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetStats(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	err := s.taskstore.ArchiveLine(common.GetLineID("make_pasta", "\t\tput water in pot"))
+	assert.Nil(err)
+
+	apiResp := new(GetStatsResponse)
+	err = s.GetStats(&GetStatsRequest{Days: 7}, apiResp)
+	assert.Nil(err)
+	assert.Equal(7, len(apiResp.Stats.Days))
+	assert.Equal(1, apiResp.Stats.Days[6].Completions)
+	assert.Equal(1, apiResp.Stats.Completions)
+
+	err = s.GetStats(&GetStatsRequest{Days: 0}, new(GetStatsResponse))
+	assert.NotNil(err)
+}
@@ -24,7 +24,7 @@ func (s *Server) GetTask(req *GetTaskRequest, resp *GetTaskResponse) error {
 	for i := range req.TaskIDs {
 		task, err := s.taskstore.GetTask(common.LineID(req.TaskIDs[i]))
 		if err != nil {
-			return err
+			return apiError(err)
 		}
 		resp.Tasks[i] = task
 	}
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"github.com/danslimmon/impulse/common"
+)
+
+type GetTaskListRequest struct {
+	ListName string
+}
+
+type GetTaskListResponse struct {
+	Response
+	Result []*common.Task
+}
+
+// GetTaskList returns the tasks in the list called req.ListName. If there's no such list, the
+// error is a NoSuchListError.
+func (s *Server) GetTaskList(req *GetTaskListRequest, resp *GetTaskListResponse) error {
+	taskList, err := s.taskstore.GetList(req.ListName)
+	if err != nil {
+		return apiError(err)
+	}
+	resp.Result = taskList
+	return nil
+}
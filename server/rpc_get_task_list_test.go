@@ -0,0 +1,29 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTaskList(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	apiReq := &GetTaskListRequest{ListName: "make_pasta"}
+	apiResp := new(GetTaskListResponse)
+	err := s.GetTaskList(apiReq, apiResp)
+	assert.Nil(err)
+	if assert.Equal(1, len(apiResp.Result)) {
+		assert.True(common.MakePasta()[0].Equal(apiResp.Result[0]))
+	}
+
+	// Nonexistent list
+	apiReq = &GetTaskListRequest{ListName: "nonexistent"}
+	err = s.GetTaskList(apiReq, new(GetTaskListResponse))
+	assert.NotNil(err)
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"fmt"
+	"time"
+)
+
+type GetTimeReportRequest struct {
+	// By is how to group the report: "node", "task", or "day".
+	By string
+}
+
+type GetTimeReportResponse struct {
+	Response
+	Entries []TimeReportEntry
+}
+
+// GetTimeReport reports how much time has been spent with each node as the top frame of its list,
+// grouped according to req.By. See TimeByNode, TimeByTask, and TimeByDay.
+func (s *Server) GetTimeReport(req *GetTimeReportRequest, resp *GetTimeReportResponse) error {
+	intervals, err := s.taskstore.GetTimeIntervals()
+	if err != nil {
+		return apiError(err)
+	}
+
+	switch req.By {
+	case "node":
+		resp.Entries = TimeByNode(intervals)
+	case "task":
+		resp.Entries = TimeByTask(intervals)
+	case "day":
+		resp.Entries = TimeByDay(intervals, time.Local)
+	default:
+		return apiError(fmt.Errorf("unknown time report grouping `%s`", req.By))
+	}
+	return nil
+}
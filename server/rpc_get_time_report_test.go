@@ -0,0 +1,32 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTimeReport(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	err := s.taskstore.ArchiveLine(common.GetLineID("make_pasta", "\t\tput water in pot"))
+	assert.Nil(err)
+
+	for _, by := range []string{"node", "task", "day"} {
+		apiReq := &GetTimeReportRequest{By: by}
+		apiResp := new(GetTimeReportResponse)
+		err = s.GetTimeReport(apiReq, apiResp)
+		assert.Nil(err)
+		assert.NotEmpty(apiResp.Entries, by)
+	}
+
+	apiReq := &GetTimeReportRequest{By: "phase of the moon"}
+	apiResp := new(GetTimeReportResponse)
+	err = s.GetTimeReport(apiReq, apiResp)
+	assert.NotNil(err)
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"github.com/danslimmon/impulse/common"
+)
+
+type GetHoistRequest struct {
+	Client string
+}
+
+type GetHoistResponse struct {
+	Response
+	// Hoist is nil if the client has no hoist.
+	Hoist *Hoist
+	// Task is the hoisted subtree, rooted at the hoisted node.
+	Task *common.Task
+}
+
+// GetHoist returns req.Client's hoist, along with the hoisted subtree.
+func (s *Server) GetHoist(req *GetHoistRequest, resp *GetHoistResponse) error {
+	h, task, err := s.taskstore.GetHoist(req.Client)
+	if err != nil {
+		return apiError(err)
+	}
+	resp.Hoist = h
+	resp.Task = task
+	return nil
+}
+
+type SetHoistRequest struct {
+	Client string
+	LineID common.LineID
+}
+
+type SetHoistResponse struct {
+	Response
+	Hoist *Hoist
+}
+
+// SetHoist hoists the node identified by req.LineID for req.Client.
+func (s *Server) SetHoist(req *SetHoistRequest, resp *SetHoistResponse) error {
+	h, err := s.taskstore.SetHoist(req.Client, req.LineID)
+	if err != nil {
+		return apiError(err)
+	}
+	resp.Hoist = h
+	return nil
+}
+
+type UnhoistRequest struct {
+	Client string
+}
+
+type UnhoistResponse struct {
+	Response
+}
+
+// Unhoist removes req.Client's hoist.
+func (s *Server) Unhoist(req *UnhoistRequest, resp *UnhoistResponse) error {
+	return apiError(s.taskstore.Unhoist(req.Client))
+}
+
+type PushRequest struct {
+	Client   string
+	ListName string
+	Task     *common.Task
+}
+
+type PushResponse struct {
+	Response
+	// LineID is the ID of the line of the pushed task's root node.
+	LineID common.LineID
+}
+
+// Push puts req.Task on top of the list identified by req.ListName, or on top of the hoisted
+// subtree if req.Client has hoisted a node in the list.
+func (s *Server) Push(req *PushRequest, resp *PushResponse) error {
+	lineId, err := s.taskstore.Push(req.Client, req.ListName, req.Task)
+	if err != nil {
+		return apiError(err)
+	}
+	resp.LineID = lineId
+	return nil
+}
+
+type PopRequest struct {
+	Client   string
+	ListName string
+}
+
+type PopResponse struct {
+	Response
+	// Path is the path (see TimeInterval) to the archived node.
+	Path []string
+}
+
+// Pop archives the top frame of the list identified by req.ListName, scoped to req.Client's hoist.
+func (s *Server) Pop(req *PopRequest, resp *PopResponse) error {
+	path, err := s.taskstore.Pop(req.Client, req.ListName)
+	if err != nil {
+		return apiError(err)
+	}
+	resp.Path = path
+	return nil
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHoist(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	lineId := common.GetLineID("make_pasta", "\tboil water")
+	err := s.SetHoist(&SetHoistRequest{Client: "cli", LineID: lineId}, new(SetHoistResponse))
+	assert.Nil(err)
+
+	pushResp := new(PushResponse)
+	err = s.Push(
+		&PushRequest{Client: "cli", ListName: "make_pasta", Task: common.NewTask(common.NewTreeNode("fill pot"))},
+		pushResp,
+	)
+	assert.Nil(err)
+	assert.Equal(common.GetLineID("make_pasta", "\t\tfill pot"), pushResp.LineID)
+
+	getResp := new(GetHoistResponse)
+	err = s.GetHoist(&GetHoistRequest{Client: "cli"}, getResp)
+	assert.Nil(err)
+	assert.Equal([]string{"make pasta", "boil water"}, getResp.Hoist.Path)
+	assert.Equal("fill pot", getResp.Task.RootNode.Children[0].Referent)
+
+	popResp := new(PopResponse)
+	err = s.Pop(&PopRequest{Client: "cli", ListName: "make_pasta"}, popResp)
+	assert.Nil(err)
+	assert.Equal([]string{"make pasta", "boil water", "fill pot"}, popResp.Path)
+
+	err = s.Unhoist(&UnhoistRequest{Client: "cli"}, new(UnhoistResponse))
+	assert.Nil(err)
+	getResp = new(GetHoistResponse)
+	err = s.GetHoist(&GetHoistRequest{Client: "cli"}, getResp)
+	assert.Nil(err)
+	assert.Nil(getResp.Hoist)
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"github.com/danslimmon/impulse/importer"
+)
+
+type ImportRequest struct {
+	// ListName is the name of the list to which the imported tasks are added. If there's no such
+	// list, it's created.
+	ListName string
+	// Format is the name of the format of Data (see importer.Formats).
+	Format string
+	Data   string
+}
+
+type ImportResponse struct {
+	Response
+	// Imported is the number of tasks imported.
+	Imported int
+}
+
+// Import parses req.Data and adds the tasks in it to the end of the list identified by
+// req.ListName.
+func (s *Server) Import(req *ImportRequest, resp *ImportResponse) error {
+	taskList, err := importer.Import(req.Format, []byte(req.Data))
+	if err != nil {
+		return apiError(err)
+	}
+	if err := s.taskstore.ImportList(req.ListName, taskList); err != nil {
+		return apiError(err)
+	}
+	resp.Imported = len(taskList)
+	return nil
+}
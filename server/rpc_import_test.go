@@ -0,0 +1,33 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	req := &ImportRequest{
+		ListName: "chores",
+		Format:   "markdown",
+		Data:     "- [ ] fix sink\n  - [ ] buy wrench\n- [ ] mow lawn\n",
+	}
+	resp := new(ImportResponse)
+	err := s.Import(req, resp)
+	assert.Nil(err)
+	assert.Equal(2, resp.Imported)
+
+	taskList, err := s.taskstore.GetList("chores")
+	assert.Nil(err)
+	assert.Equal("buy wrench", taskList[0].RootNode.Children[0].Referent)
+
+	req.Format = "csv"
+	err = s.Import(req, new(ImportResponse))
+	assert.NotNil(err)
+}
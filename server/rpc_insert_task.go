@@ -0,0 +1,20 @@
+package server
+
+import (
+	"github.com/danslimmon/impulse/common"
+)
+
+type InsertTaskRequest struct {
+	// LineID is the position after which the task is inserted (see common.LineID).
+	LineID common.LineID
+	Task   *common.Task
+}
+
+type InsertTaskResponse struct {
+	Response
+}
+
+// InsertTask inserts req.Task at the position identified by req.LineID.
+func (s *Server) InsertTask(req *InsertTaskRequest, resp *InsertTaskResponse) error {
+	return apiError(s.taskstore.InsertTask(req.LineID, req.Task))
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertTask(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	apiReq := &InsertTaskRequest{
+		LineID: common.LineID("make_pasta:0"),
+		Task:   common.NewTask(common.NewTreeNode("check Twitter")),
+	}
+	err := s.InsertTask(apiReq, new(InsertTaskResponse))
+	assert.Nil(err)
+
+	taskList, err := s.taskstore.GetList("make_pasta")
+	assert.Nil(err)
+	assert.Equal(2, len(taskList))
+	assert.Equal("check Twitter", common.Top(taskList).Referent)
+
+	// Empty referents can't be represented
+	apiReq.Task = common.NewTask(common.NewTreeNode(""))
+	err = s.InsertTask(apiReq, new(InsertTaskResponse))
+	assert.NotNil(err)
+}
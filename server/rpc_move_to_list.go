@@ -0,0 +1,21 @@
+package server
+
+import (
+	"github.com/danslimmon/impulse/common"
+)
+
+type MoveToListRequest struct {
+	LineID common.LineID
+	// Dest is the position to which the subtree is moved, interpreted as by InsertTask.
+	Dest common.LineID
+}
+
+type MoveToListResponse struct {
+	Response
+}
+
+// MoveToList moves the node identified by req.LineID, along with its descendants, to req.Dest. See
+// BasicTaskstore.MoveToList for details.
+func (s *Server) MoveToList(req *MoveToListRequest, resp *MoveToListResponse) error {
+	return apiError(s.taskstore.MoveToList(req.LineID, req.Dest))
+}
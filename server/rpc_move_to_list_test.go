@@ -0,0 +1,32 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveToList(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	apiReq := &MoveToListRequest{
+		LineID: common.GetLineID("multiple_nested", "\tsubtask 0"),
+		Dest:   common.LineID("empty:0"),
+	}
+	err := s.MoveToList(apiReq, new(MoveToListResponse))
+	assert.Nil(err)
+
+	taskList, err := s.taskstore.GetList("empty")
+	assert.Nil(err)
+	assert.Equal(1, len(taskList))
+	assert.Equal("subtask 0", taskList[0].RootNode.Referent)
+
+	// The node isn't there any more
+	err = s.MoveToList(apiReq, new(MoveToListResponse))
+	assert.NotNil(err)
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"github.com/danslimmon/impulse/common"
+)
+
+type GetNoteRequest struct {
+	LineID common.LineID
+}
+
+type GetNoteResponse struct {
+	Response
+	Note string
+}
+
+// GetNote returns the note attached to the node identified by req.LineID.
+func (s *Server) GetNote(req *GetNoteRequest, resp *GetNoteResponse) error {
+	note, err := s.taskstore.GetNote(req.LineID)
+	if err != nil {
+		return apiError(err)
+	}
+	resp.Note = note
+	return nil
+}
+
+type SetNoteRequest struct {
+	LineID common.LineID
+	// Note is the node's new note. If it's empty, the node's note is removed.
+	Note string
+}
+
+type SetNoteResponse struct {
+	Response
+}
+
+// SetNote replaces the note attached to the node identified by req.LineID.
+func (s *Server) SetNote(req *SetNoteRequest, resp *SetNoteResponse) error {
+	return apiError(s.taskstore.SetNote(req.LineID, req.Note))
+}
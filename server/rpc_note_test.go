@@ -0,0 +1,28 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNote(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	lineId := common.GetLineID("make_pasta", "\tboil water")
+	err := s.SetNote(&SetNoteRequest{LineID: lineId, Note: "salt it\nlike the sea\n"}, new(SetNoteResponse))
+	assert.Nil(err)
+
+	getResp := new(GetNoteResponse)
+	err = s.GetNote(&GetNoteRequest{LineID: lineId}, getResp)
+	assert.Nil(err)
+	assert.Equal("salt it\nlike the sea", getResp.Note)
+
+	err = s.GetNote(&GetNoteRequest{LineID: common.GetLineID("make_pasta", "nonexistent")}, getResp)
+	assert.NotNil(err)
+}
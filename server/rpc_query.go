@@ -0,0 +1,23 @@
+package server
+
+type QueryRequest struct {
+	// ListName is the name of the list to query. If it's empty, all lists are queried.
+	ListName string
+	// Query is in the language described by common.Query.
+	Query string
+}
+
+type QueryResponse struct {
+	Response
+	Results []SearchResult
+}
+
+// Query returns the nodes that match req.Query in the list identified by req.ListName.
+func (s *Server) Query(req *QueryRequest, resp *QueryResponse) error {
+	rslt, err := s.taskstore.Query(req.ListName, req.Query)
+	if err != nil {
+		return apiError(err)
+	}
+	resp.Results = rslt
+	return nil
+}
@@ -0,0 +1,24 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	resp := new(QueryResponse)
+	err := s.Query(&QueryRequest{ListName: "make_pasta", Query: "blocked"}, resp)
+	assert.Nil(err)
+	assert.Equal(1, len(resp.Results))
+	assert.Equal([]string{"make pasta", "[b cooked]"}, resp.Results[0].Path)
+
+	err = s.Query(&QueryRequest{Query: "(leaf"}, new(QueryResponse))
+	assert.NotNil(err)
+}
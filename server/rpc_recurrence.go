@@ -0,0 +1,76 @@
+package server
+
+import (
+	"github.com/danslimmon/impulse/common"
+)
+
+type AddRecurrenceRequest struct {
+	ListName string
+	// Schedule is the spec of the recurrence's schedule (see common.Schedule).
+	Schedule string
+	Template *common.Task
+}
+
+type AddRecurrenceResponse struct {
+	Response
+	Recurrence *Recurrence
+}
+
+// AddRecurrence adds a recurrence that inserts req.Template into the list identified by
+// req.ListName according to req.Schedule.
+func (s *Server) AddRecurrence(req *AddRecurrenceRequest, resp *AddRecurrenceResponse) error {
+	rec, err := s.taskstore.AddRecurrence(req.ListName, req.Schedule, req.Template)
+	if err != nil {
+		return apiError(err)
+	}
+	resp.Recurrence = rec
+	return nil
+}
+
+type GetRecurrencesRequest struct {
+	// ListName is the name of the list whose recurrences to return. If it's empty, the recurrences
+	// for all lists are returned.
+	ListName string
+}
+
+type GetRecurrencesResponse struct {
+	Response
+	Recurrences []*Recurrence
+}
+
+// GetRecurrences returns the recurrences for the list identified by req.ListName.
+func (s *Server) GetRecurrences(req *GetRecurrencesRequest, resp *GetRecurrencesResponse) error {
+	recs, err := s.taskstore.GetRecurrences(req.ListName)
+	if err != nil {
+		return apiError(err)
+	}
+	resp.Recurrences = recs
+	return nil
+}
+
+type SetRecurrencePausedRequest struct {
+	ID     string
+	Paused bool
+}
+
+type SetRecurrencePausedResponse struct {
+	Response
+}
+
+// SetRecurrencePaused pauses or resumes the recurrence identified by req.ID.
+func (s *Server) SetRecurrencePaused(req *SetRecurrencePausedRequest, resp *SetRecurrencePausedResponse) error {
+	return apiError(s.taskstore.SetRecurrencePaused(req.ID, req.Paused))
+}
+
+type DeleteRecurrenceRequest struct {
+	ID string
+}
+
+type DeleteRecurrenceResponse struct {
+	Response
+}
+
+// DeleteRecurrence deletes the recurrence identified by req.ID.
+func (s *Server) DeleteRecurrence(req *DeleteRecurrenceRequest, resp *DeleteRecurrenceResponse) error {
+	return apiError(s.taskstore.DeleteRecurrence(req.ID))
+}
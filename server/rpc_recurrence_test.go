@@ -0,0 +1,44 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecurrence(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	addReq := &AddRecurrenceRequest{
+		ListName: "make_pasta",
+		Schedule: "every weekday 9:00",
+		Template: common.NewTask(common.NewTreeNode("check email")),
+	}
+	addResp := new(AddRecurrenceResponse)
+	err := s.AddRecurrence(addReq, addResp)
+	assert.Nil(err)
+	assert.Equal("1", addResp.Recurrence.ID)
+
+	err = s.SetRecurrencePaused(&SetRecurrencePausedRequest{ID: "1", Paused: true}, new(SetRecurrencePausedResponse))
+	assert.Nil(err)
+
+	getResp := new(GetRecurrencesResponse)
+	err = s.GetRecurrences(&GetRecurrencesRequest{ListName: "make_pasta"}, getResp)
+	assert.Nil(err)
+	assert.Equal(1, len(getResp.Recurrences))
+	assert.Equal("every weekday 9:00", getResp.Recurrences[0].Schedule)
+	assert.True(getResp.Recurrences[0].Paused)
+
+	err = s.DeleteRecurrence(&DeleteRecurrenceRequest{ID: "1"}, new(DeleteRecurrenceResponse))
+	assert.Nil(err)
+
+	getResp = new(GetRecurrencesResponse)
+	err = s.GetRecurrences(&GetRecurrencesRequest{}, getResp)
+	assert.Nil(err)
+	assert.Equal(0, len(getResp.Recurrences))
+}
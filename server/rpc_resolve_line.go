@@ -0,0 +1,27 @@
+package server
+
+import (
+	"github.com/danslimmon/impulse/common"
+)
+
+type ResolveLineRequest struct {
+	ListName string
+	// Text is the line's text as it appears in the list's data, indentation included.
+	Text string
+}
+
+type ResolveLineResponse struct {
+	Response
+	LineID common.LineID
+}
+
+// ResolveLine returns the ID of the line in the list called req.ListName whose text is req.Text.
+// See BasicTaskstore.ResolveLine.
+func (s *Server) ResolveLine(req *ResolveLineRequest, resp *ResolveLineResponse) error {
+	lineId, err := s.taskstore.ResolveLine(req.ListName, req.Text)
+	if err != nil {
+		return apiError(err)
+	}
+	resp.LineID = lineId
+	return nil
+}
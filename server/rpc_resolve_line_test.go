@@ -0,0 +1,29 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveLine(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	resp := new(ResolveLineResponse)
+	err := s.ResolveLine(&ResolveLineRequest{ListName: "multiple_nested", Text: "\tsubtask 0"}, resp)
+	assert.Nil(err)
+	assert.Equal(common.GetLineID("multiple_nested", "\tsubtask 0"), resp.LineID)
+
+	// No such line
+	err = s.ResolveLine(&ResolveLineRequest{ListName: "multiple_nested", Text: "subtask 0"}, new(ResolveLineResponse))
+	assert.NotNil(err)
+
+	// No such list
+	err = s.ResolveLine(&ResolveLineRequest{ListName: "nonexistent", Text: "task 0"}, new(ResolveLineResponse))
+	assert.NotNil(err)
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+type GetStaleNodesRequest struct {
+	// ListName is the name of the list to review, or empty to review all lists.
+	ListName string
+	// Age is how long a node must have gone untouched to be stale.
+	Age time.Duration
+}
+
+type GetStaleNodesResponse struct {
+	Response
+	Nodes []StaleNode
+}
+
+// GetStaleNodes returns the nodes that haven't been touched for req.Age, oldest first. See
+// BasicTaskstore.StaleNodes.
+func (s *Server) GetStaleNodes(req *GetStaleNodesRequest, resp *GetStaleNodesResponse) error {
+	nodes, err := s.taskstore.StaleNodes(req.ListName, req.Age)
+	if err != nil {
+		return apiError(err)
+	}
+	resp.Nodes = nodes
+	return nil
+}
+
+type TouchRequest struct {
+	LineID common.LineID
+}
+
+type TouchResponse struct {
+	Response
+}
+
+// Touch marks the node identified by req.LineID as touched now.
+func (s *Server) Touch(req *TouchRequest, resp *TouchResponse) error {
+	return apiError(s.taskstore.Touch(req.LineID))
+}
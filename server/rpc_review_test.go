@@ -0,0 +1,30 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReview(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	apiResp := new(GetStaleNodesResponse)
+	err := s.GetStaleNodes(&GetStaleNodesRequest{ListName: "multiple_nested"}, apiResp)
+	assert.Nil(err)
+	assert.Equal(2, len(apiResp.Nodes))
+	assert.Equal(common.GetLineID("multiple_nested", "task 0"), apiResp.Nodes[0].LineID)
+
+	err = s.Touch(&TouchRequest{LineID: apiResp.Nodes[0].LineID}, new(TouchResponse))
+	assert.Nil(err)
+	err = s.Touch(&TouchRequest{LineID: common.GetLineID("multiple_nested", "nonexistent")}, new(TouchResponse))
+	assert.NotNil(err)
+
+	err = s.GetStaleNodes(&GetStaleNodesRequest{ListName: "nonexistent"}, new(GetStaleNodesResponse))
+	assert.NotNil(err)
+}
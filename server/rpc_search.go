@@ -0,0 +1,25 @@
+package server
+
+type SearchRequest struct {
+	Query string
+	// Mode is SearchSubstring, SearchCaseInsensitive, or SearchRegex. If it's empty,
+	// SearchSubstring is used.
+	Mode string
+	// History determines whether lines in the history file are searched too.
+	History bool
+}
+
+type SearchResponse struct {
+	Response
+	Results []SearchResult
+}
+
+// Search returns the nodes in all lists that match req.Query.
+func (s *Server) Search(req *SearchRequest, resp *SearchResponse) error {
+	rslt, err := s.taskstore.Search(req.Query, req.Mode, req.History)
+	if err != nil {
+		return apiError(err)
+	}
+	resp.Results = rslt
+	return nil
+}
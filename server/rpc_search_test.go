@@ -0,0 +1,25 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	resp := new(SearchResponse)
+	err := s.Search(&SearchRequest{Query: "Drain", Mode: SearchCaseInsensitive}, resp)
+	assert.Nil(err)
+	assert.Equal(1, len(resp.Results))
+	assert.Equal("make_pasta", resp.Results[0].ListName)
+	assert.Equal([]string{"make pasta", "drain pasta"}, resp.Results[0].Path)
+
+	err = s.Search(&SearchRequest{Query: "[", Mode: SearchRegex}, new(SearchResponse))
+	assert.NotNil(err)
+}
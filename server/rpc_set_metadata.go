@@ -0,0 +1,29 @@
+package server
+
+import (
+	"github.com/danslimmon/impulse/common"
+)
+
+type SetMetadataRequest struct {
+	LineID   common.LineID
+	Metadata common.Metadata
+}
+
+type SetMetadataResponse struct {
+	Response
+	// LineID is the ID of the line after its metadata has been changed.
+	LineID common.LineID
+}
+
+// SetMetadata replaces the metadata of the line identified by req.LineID with req.Metadata.
+//
+// Since a line's ID depends on its content, changing the line's metadata changes its ID. The new ID
+// is returned in resp.LineID.
+func (s *Server) SetMetadata(req *SetMetadataRequest, resp *SetMetadataResponse) error {
+	lineId, err := s.taskstore.SetMetadata(req.LineID, req.Metadata)
+	if err != nil {
+		return apiError(err)
+	}
+	resp.LineID = lineId
+	return nil
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetMetadata(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	apiReq := &SetMetadataRequest{
+		LineID: common.GetLineID("make_pasta", "\tboil water"),
+		Metadata: common.Metadata{
+			Tags:     []string{"kitchen"},
+			Estimate: 10 * time.Minute,
+		},
+	}
+	apiResp := new(SetMetadataResponse)
+	err := s.SetMetadata(apiReq, apiResp)
+	assert.Nil(err)
+	assert.Equal(common.GetLineID("make_pasta", "\tboil water #kitchen ~10m"), apiResp.LineID)
+
+	taskList, err := s.taskstore.GetList("make_pasta")
+	assert.Nil(err)
+	boilWater := taskList[0].RootNode.Children[0]
+	assert.Equal("boil water #kitchen ~10m", boilWater.Referent)
+	assert.Equal(3, len(boilWater.Children))
+
+	// Clear the metadata again
+	apiReq = &SetMetadataRequest{LineID: apiResp.LineID}
+	err = s.SetMetadata(apiReq, apiResp)
+	assert.Nil(err)
+	assert.Equal(common.GetLineID("make_pasta", "\tboil water"), apiResp.LineID)
+
+	// Nonexistent line
+	apiReq = &SetMetadataRequest{LineID: common.GetLineID("make_pasta", "\tburn self on pot")}
+	err = s.SetMetadata(apiReq, apiResp)
+	assert.NotNil(err)
+}
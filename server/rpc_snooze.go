@@ -0,0 +1,30 @@
+package server
+
+import (
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+type SnoozeRequest struct {
+	LineID common.LineID
+	Until  time.Time
+}
+
+type SnoozeResponse struct {
+	Response
+	// LineID is the ID of the line after it has been snoozed.
+	LineID common.LineID
+}
+
+// Snooze hides the line identified by req.LineID from the stack until req.Until.
+//
+// Snoozing a line changes its ID. The new ID is returned in resp.LineID.
+func (s *Server) Snooze(req *SnoozeRequest, resp *SnoozeResponse) error {
+	lineId, err := s.taskstore.Snooze(req.LineID, req.Until)
+	if err != nil {
+		return apiError(err)
+	}
+	resp.LineID = lineId
+	return nil
+}
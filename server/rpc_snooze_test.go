@@ -0,0 +1,32 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnooze(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	until := time.Date(2999, 1, 1, 9, 0, 30, 0, time.Local)
+	apiReq := &SnoozeRequest{
+		LineID: common.GetLineID("make_pasta", "\tboil water"),
+		Until:  until,
+	}
+	apiResp := new(SnoozeResponse)
+	err := s.Snooze(apiReq, apiResp)
+	assert.Nil(err)
+	// not-before times are rounded up to the minute
+	assert.Equal(common.GetLineID("make_pasta", "\tboil water after:2999-01-01T09:01"), apiResp.LineID)
+
+	taskList, err := s.taskstore.GetList("make_pasta")
+	assert.Nil(err)
+	assert.Equal("put pasta in water", common.Top(taskList).Referent)
+}
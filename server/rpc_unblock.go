@@ -0,0 +1,19 @@
+package server
+
+import (
+	"github.com/danslimmon/impulse/common"
+)
+
+type UnblockRequest struct {
+	LineID common.LineID
+}
+
+type UnblockResponse struct {
+	Response
+}
+
+// Unblock marks the line identified by req.LineID as no longer blocked. See
+// BasicTaskstore.Unblock for details.
+func (s *Server) Unblock(req *UnblockRequest, resp *UnblockResponse) error {
+	return apiError(s.taskstore.Unblock(req.LineID))
+}
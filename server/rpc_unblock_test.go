@@ -0,0 +1,32 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnblock(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	apiReq := &UnblockRequest{LineID: common.GetLineID("make_pasta", "\t[b cooked]")}
+	apiResp := new(UnblockResponse)
+	err := s.Unblock(apiReq, apiResp)
+	assert.Nil(err)
+
+	taskList, err := s.taskstore.GetList("make_pasta")
+	assert.Nil(err)
+	for _, ch := range taskList[0].RootNode.Children {
+		assert.False(ch.Blocked())
+	}
+
+	// Unblocking a line that isn't blocked is an error
+	apiReq = &UnblockRequest{LineID: common.GetLineID("make_pasta", "\tdrain pasta")}
+	err = s.Unblock(apiReq, apiResp)
+	assert.NotNil(err)
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"time"
+)
+
+// longPollTimeout is the longest that WaitForChanges will block before returning an empty response.
+const longPollTimeout = 30 * time.Second
+
+type WaitForChangesRequest struct {
+	// ListName is the name of the task list whose changes should be returned. If ListName is empty,
+	// changes to all lists are returned.
+	ListName string
+	// AfterSeq is the Seq of the last ChangeEvent the caller has seen. Only events with a greater
+	// Seq are returned.
+	//
+	// To receive only the events that occur from now on, get AfterSeq from GetChangeSeq.
+	AfterSeq uint64
+}
+
+type WaitForChangesResponse struct {
+	Response
+	Events []ChangeEvent
+}
+
+// WaitForChanges long-polls for changes to task lists. The response's Events attribute contains
+// the events that occurred after req.AfterSeq, in the order in which they occurred.
+//
+// If no such events have occurred, WaitForChanges blocks until one does. After longPollTimeout,
+// WaitForChanges gives up and returns an empty Events slice, in which case the caller should just
+// make the same request again.
+//
+// resp.StateID is set to the StateID of the last event returned.
+func (s *Server) WaitForChanges(req *WaitForChangesRequest, resp *WaitForChangesResponse) error {
+	resp.Events = s.taskstore.Feed().Since(req.ListName, req.AfterSeq, longPollTimeout)
+	if len(resp.Events) > 0 {
+		resp.StateID = resp.Events[len(resp.Events)-1].StateID
+	}
+	return nil
+}
+
+type GetChangeSeqRequest struct{}
+
+type GetChangeSeqResponse struct {
+	Response
+	Seq uint64
+}
+
+// GetChangeSeq returns the Seq of the most recent ChangeEvent, or 0 if nothing has changed since
+// the server started.
+func (s *Server) GetChangeSeq(req *GetChangeSeqRequest, resp *GetChangeSeqResponse) error {
+	resp.Seq = s.taskstore.Feed().Seq()
+	return nil
+}
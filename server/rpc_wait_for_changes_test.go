@@ -0,0 +1,58 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForChanges(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s, cleanup := NewServerWithTestdata()
+	defer cleanup()
+
+	seqReq := new(GetChangeSeqRequest)
+	seqResp := new(GetChangeSeqResponse)
+	err := s.GetChangeSeq(seqReq, seqResp)
+	assert.Nil(err)
+	assert.Equal(uint64(0), seqResp.Seq)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		s.taskstore.InsertTask(
+			common.LineID("make_pasta:0"),
+			common.NewTask(common.NewTreeNode("alpha")),
+		)
+	}()
+
+	apiReq := &WaitForChangesRequest{ListName: "make_pasta", AfterSeq: seqResp.Seq}
+	apiResp := new(WaitForChangesResponse)
+	err = s.WaitForChanges(apiReq, apiResp)
+	assert.Nil(err)
+	assert.Equal(1, len(apiResp.Events))
+
+	ev := apiResp.Events[0]
+	assert.Equal("make_pasta", ev.ListName)
+	assert.Equal("InsertTask", ev.Op)
+	assert.Equal(common.GetLineID("make_pasta", "alpha"), ev.LineID)
+	assert.Equal(ev.StateID, apiResp.StateID)
+
+	// the StateID should reflect the list's new contents
+	b, err := s.taskstore.(*BasicTaskstore).datastore.Get("make_pasta")
+	assert.Nil(err)
+	assert.Equal(StateID(b), ev.StateID)
+
+	// events that have already happened are returned immediately
+	err = s.taskstore.ArchiveLine(common.GetLineID("make_pasta", "alpha"))
+	assert.Nil(err)
+	apiReq = &WaitForChangesRequest{ListName: "make_pasta", AfterSeq: ev.Seq}
+	apiResp = new(WaitForChangesResponse)
+	err = s.WaitForChanges(apiReq, apiResp)
+	assert.Nil(err)
+	assert.Equal(1, len(apiResp.Events))
+	assert.Equal("ArchiveLine", apiResp.Events[0].Op)
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// schedulerMaxWait is the longest Schedule sleeps between checks for snoozed nodes and
+// recurrences, even if it knows of none that are due.
+const schedulerMaxWait = time.Minute
+
+// reveal publishes a "Reveal" event for each snoozed node whose not-before time falls in the
+// interval (since, now].
+//
+// reveal returns the earliest not-before time after now, or the zero time if there is none.
+func (ts *BasicTaskstore) reveal(since, now time.Time) (time.Time, error) {
+	var next time.Time
+
+	names, err := ts.ListNames()
+	if err != nil {
+		return next, err
+	}
+	for _, name := range names {
+		b, err := ts.datastore.Get(name)
+		if err != nil {
+			continue
+		}
+		taskList, err := ts.unmarshalList(name, b)
+		if err != nil {
+			continue
+		}
+
+		revealed := make([]*common.TreeNode, 0)
+		for _, t := range taskList {
+			t.RootNode.Walk(func(n *common.TreeNode) error {
+				nb := n.Metadata().NotBefore
+				if nb == nil {
+					return nil
+				}
+				if nb.After(since) && !nb.After(now) {
+					revealed = append(revealed, n)
+				} else if nb.After(now) && (next.IsZero() || nb.Before(next)) {
+					next = *nb
+				}
+				return nil
+			})
+		}
+
+		for _, n := range revealed {
+			ts.publish(name, "Reveal", ts.nodeLineId(name, n), b)
+		}
+	}
+	return next, nil
+}
+
+// Schedule reveals snoozed nodes and instantiates recurrences when their time comes, until stop is
+// closed.
+//
+// When a node's not-before time arrives, a "Reveal" event is published, and the list's top frame
+// is re-evaluated for time tracking. The node's metadata is left as it is; once its time has
+// passed, it simply stops being snoozed.
+//
+// When a recurrence comes due, a copy of its template is inserted into its list (see Recurrence).
+func (ts *BasicTaskstore) Schedule(stop <-chan struct{}) {
+	since := time.Now()
+	for {
+		// Grab this before checking, so that we don't miss any changes that happen during the
+		// check.
+		changed := ts.feed.Changed()
+
+		now := time.Now()
+		next, _ := ts.reveal(since, now)
+		since = now
+		if due, _ := ts.recur(now); !due.IsZero() && (next.IsZero() || due.Before(next)) {
+			next = due
+		}
+
+		wait := schedulerMaxWait
+		if !next.IsZero() && next.Sub(now) < wait {
+			wait = next.Sub(now)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-changed:
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicTaskstore_reveal(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	err := ds.Put("plumbing", []byte("\tcall plumber after:2026-10-19T09:00\nfix sink after:2026-10-20T09:00\n"))
+	assert.Nil(err)
+
+	at := func(s string) time.Time {
+		t, err := time.ParseInLocation("2006-01-02T15:04", s, time.Local)
+		if err != nil {
+			panic(err.Error())
+		}
+		return t
+	}
+
+	// nothing due yet
+	next, err := ts.reveal(at("2026-10-19T07:00"), at("2026-10-19T08:00"))
+	assert.Nil(err)
+	assert.True(at("2026-10-19T09:00").Equal(next))
+	assert.Equal(0, len(ts.Feed().Since("plumbing", 0, 0)))
+
+	// call plumber is due
+	next, err = ts.reveal(at("2026-10-19T08:00"), at("2026-10-19T09:00"))
+	assert.Nil(err)
+	assert.True(at("2026-10-20T09:00").Equal(next))
+	evs := ts.Feed().Since("plumbing", 0, 0)
+	assert.Equal(1, len(evs))
+	assert.Equal("Reveal", evs[0].Op)
+	assert.Equal(common.GetLineID("plumbing", "\tcall plumber after:2026-10-19T09:00"), evs[0].LineID)
+
+	// nothing else left
+	next, err = ts.reveal(at("2026-10-20T08:00"), at("2026-10-20T10:00"))
+	assert.Nil(err)
+	assert.True(next.IsZero())
+	assert.Equal(2, len(ts.Feed().Since("plumbing", 0, 0)))
+}
+
+// Tests that Schedule reveals nodes when their time comes.
+//
+// This can take up to a minute, since not-before times are only precise to the minute.
+func TestBasicTaskstore_Schedule(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping slow scheduler test in short mode")
+	}
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go ts.Schedule(stop)
+
+	// snooze until the start of the next minute, so that we don't have to wait long
+	until := time.Now().Truncate(time.Minute).Add(time.Minute)
+	_, err := ts.Snooze(common.GetLineID("multiple_nested", "task 1"), until)
+	assert.Nil(err)
+
+	evs := ts.Feed().Since("multiple_nested", 1, 2*time.Minute)
+	assert.Equal(1, len(evs))
+	assert.Equal("Reveal", evs[0].Op)
+	assert.False(time.Now().Before(until))
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// Search modes, which determine how a query is matched against text.
+const (
+	// SearchSubstring matches text that contains the query.
+	SearchSubstring = "substring"
+	// SearchCaseInsensitive matches text that contains the query, ignoring case.
+	SearchCaseInsensitive = "icase"
+	// SearchRegex matches text that matches the query as a regular expression (see package
+	// regexp).
+	SearchRegex = "regex"
+)
+
+// SearchResult is a node, or a line of the history file, that matched a search query (see Search)
+// or a structural query (see Query).
+type SearchResult struct {
+	// ListName is the name of the list containing the node, or "history" for a history line.
+	ListName string
+	// LineID identifies the node. It's empty for a history line.
+	LineID common.LineID
+	// Path is the referents of the node and its ancestors, from the task's root node down to the
+	// node itself. For a history line, it's just the archived line's text.
+	Path []string
+	// Time is when a history line was archived. It's the zero time for a node.
+	Time time.Time
+}
+
+// searchResult returns the SearchResult for n, a node in the list identified by listName.
+func (ts *BasicTaskstore) searchResult(listName string, n *common.TreeNode) SearchResult {
+	return SearchResult{
+		ListName: listName,
+		LineID:   ts.nodeLineId(listName, n),
+		Path:     nodePath(n),
+	}
+}
+
+// searchMatcher returns a function that determines whether a string matches query in the given
+// mode. An empty mode means SearchSubstring.
+func searchMatcher(query, mode string) (func(string) bool, error) {
+	switch mode {
+	case "", SearchSubstring:
+		return func(s string) bool { return strings.Contains(s, query) }, nil
+	case SearchCaseInsensitive:
+		query = strings.ToLower(query)
+		return func(s string) bool { return strings.Contains(strings.ToLower(s), query) }, nil
+	case SearchRegex:
+		re, err := regexp.Compile(query)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+	return nil, fmt.Errorf("unknown search mode `%s`", mode)
+}
+
+// Search returns the nodes in all lists whose referents match query, in the given mode (see
+// SearchSubstring, etc.). Lists are searched in lexical order, and each list from the top down.
+//
+// If history is true, archived lines in the history file that match are returned too, after all the
+// nodes, oldest first.
+//
+// Lists that can't be parsed are skipped.
+func (ts *BasicTaskstore) Search(query, mode string, history bool) ([]SearchResult, error) {
+	match, err := searchMatcher(query, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := ts.ListNames()
+	if err != nil {
+		return nil, err
+	}
+	rslt := make([]SearchResult, 0)
+	for _, name := range names {
+		taskList, err := ts.GetList(name)
+		if err != nil {
+			continue
+		}
+		for _, t := range taskList {
+			t.RootNode.WalkFromTop(func(n *common.TreeNode) error {
+				if !match(n.Referent) {
+					return nil
+				}
+				rslt = append(rslt, ts.searchResult(name, n))
+				return nil
+			})
+		}
+	}
+
+	if !history {
+		return rslt, nil
+	}
+	b, err := ts.datastore.Get("history")
+	if err != nil {
+		// No history file yet
+		return rslt, nil
+	}
+	for _, line := range splitHistory(b) {
+		entry, err := parseHistoryLine(line)
+		if err != nil || entry.Kind != "archive" {
+			continue
+		}
+		text := strings.TrimLeft(entry.Text, " \t")
+		if match(text) {
+			rslt = append(rslt, SearchResult{
+				ListName: "history",
+				Path:     []string{text},
+				Time:     entry.Time,
+			})
+		}
+	}
+	return rslt, nil
+}
+
+// Query returns the nodes that match q, a query in the language described by common.Query, in
+// the list identified by listName. If listName is empty, all lists are queried, in lexical order,
+// and lists that can't be parsed are skipped.
+func (ts *BasicTaskstore) Query(listName, q string) ([]SearchResult, error) {
+	query, err := common.ParseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{listName}
+	if listName == "" {
+		names, err = ts.ListNames()
+		if err != nil {
+			return nil, err
+		}
+	}
+	now := time.Now()
+	rslt := make([]SearchResult, 0)
+	for _, name := range names {
+		taskList, err := ts.GetList(name)
+		if err != nil {
+			if listName == "" {
+				continue
+			}
+			return nil, err
+		}
+		for _, n := range query.Select(taskList, now) {
+			rslt = append(rslt, ts.searchResult(name, n))
+		}
+	}
+	return rslt, nil
+}
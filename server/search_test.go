@@ -0,0 +1,183 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicTaskstore_Search(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	type testCase struct {
+		Query   string
+		Mode    string
+		ExpIDs  []common.LineID
+		ExpPath []string
+	}
+
+	testCases := []testCase{
+		testCase{
+			Query: "subsub",
+			Mode:  SearchSubstring,
+			ExpIDs: []common.LineID{
+				common.GetLineID("multiple_nested", "\t\tsubsubtask 0"),
+			},
+			ExpPath: []string{"task 0", "subtask 0", "subsubtask 0"},
+		},
+		testCase{
+			Query: "WATER",
+			Mode:  SearchCaseInsensitive,
+			ExpIDs: []common.LineID{
+				common.GetLineID("make_pasta", "\t\tput water in pot"),
+				common.GetLineID("make_pasta", "\tboil water"),
+				common.GetLineID("make_pasta", "\tput pasta in water"),
+			},
+			ExpPath: []string{"make pasta", "boil water", "put water in pot"},
+		},
+		testCase{
+			Query:  "WATER",
+			Mode:   SearchSubstring,
+			ExpIDs: []common.LineID{},
+		},
+		testCase{
+			Query: `^(sub)?task 1$`,
+			Mode:  SearchRegex,
+			ExpIDs: []common.LineID{
+				common.GetLineID("multiple_nested", "\tsubtask 1"),
+				common.GetLineID("multiple_nested", "task 1"),
+			},
+			ExpPath: []string{"task 1", "subtask 1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		rslt, err := ts.Search(tc.Query, tc.Mode, false)
+		assert.Nil(err)
+		ids := make([]common.LineID, 0)
+		for _, r := range rslt {
+			ids = append(ids, r.LineID)
+		}
+		assert.Equal(tc.ExpIDs, ids, tc.Query)
+		if len(rslt) > 0 {
+			assert.Equal(tc.ExpPath, rslt[0].Path, tc.Query)
+		}
+	}
+
+	_, err := ts.Search("(", SearchRegex, false)
+	assert.NotNil(err)
+	_, err = ts.Search("pasta", "fuzzy", false)
+	assert.NotNil(err)
+}
+
+func TestBasicTaskstore_Search_History(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	err := ts.ArchiveLine(common.GetLineID("make_pasta", "\t\tput water in pot"))
+	assert.Nil(err)
+
+	rslt, err := ts.Search("put water", SearchSubstring, false)
+	assert.Nil(err)
+	assert.Equal(0, len(rslt))
+
+	rslt, err = ts.Search("put water", SearchSubstring, true)
+	assert.Nil(err)
+	assert.Equal(1, len(rslt))
+	assert.Equal("history", rslt[0].ListName)
+	assert.Equal(common.LineID(""), rslt[0].LineID)
+	assert.Equal([]string{"put water in pot"}, rslt[0].Path)
+	assert.False(rslt[0].Time.IsZero())
+
+	// Only archived lines are searched, not the other kinds of history entry
+	err = ds.Append("history", []byte(
+		"2021-12-30T19:00:00 [push make_pasta] put water in kettle\n"+
+			"2021-12-30T19:01:00 [focus completed 1m0s] make pasta > put water in kettle\n"+
+			"2021-12-30T19:02:00 [move out make_pasta to pers] make pasta > put water in kettle\n"+
+			"2021-12-30T19:02:00 [move in pers from make_pasta] put water in kettle\n"+
+			"2021-12-30T19:03:00 [interrupt pers 1] put water in kettle\tcheck Twitter\n"+
+			"2021-12-30T19:04:00 [archive pers] put water in kettle\n",
+	))
+	assert.Nil(err)
+	rslt, err = ts.Search("put water", SearchSubstring, true)
+	assert.Nil(err)
+	if assert.Equal(2, len(rslt)) {
+		assert.Equal([]string{"put water in pot"}, rslt[0].Path)
+		assert.Equal([]string{"put water in kettle"}, rslt[1].Path)
+	}
+}
+
+// Tests that each archived line is found separately, with the time at which it was archived.
+func TestBasicTaskstore_Search_History_Multiple(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	err := ds.Put("history", []byte("2021-12-30T19:24:48 [archive make_pasta] \t\tput pot in sink\n"))
+	assert.Nil(err)
+	err = ts.ArchiveLine(common.GetLineID("make_pasta", "\t\tput water in pot"))
+	assert.Nil(err)
+	err = ts.ArchiveLine(common.GetLineID("make_pasta", "\t\tput pot on burner"))
+	assert.Nil(err)
+
+	rslt, err := ts.Search("put", SearchSubstring, true)
+	assert.Nil(err)
+	paths := make([][]string, 0)
+	for _, r := range rslt {
+		if r.ListName == "history" {
+			paths = append(paths, r.Path)
+		}
+	}
+	assert.Equal([][]string{{"put pot in sink"}, {"put water in pot"}, {"put pot on burner"}}, paths)
+
+	n := len(rslt)
+	assert.Equal(time.Date(2021, 12, 30, 19, 24, 48, 0, time.Local), rslt[n-3].Time)
+	assert.True(time.Since(rslt[n-2].Time) < time.Minute)
+	assert.True(time.Since(rslt[n-1].Time) < time.Minute)
+}
+
+func TestBasicTaskstore_Query(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	rslt, err := ts.Query("", "depth>1")
+	assert.Nil(err)
+	paths := make([][]string, 0)
+	for _, r := range rslt {
+		paths = append(paths, r.Path)
+	}
+	assert.Equal([][]string{
+		[]string{"make pasta", "boil water", "put water in pot"},
+		[]string{"make pasta", "boil water", "put pot on burner"},
+		[]string{"make pasta", "boil water", "turn burner on"},
+		[]string{"task 0", "subtask 0", "subsubtask 0"},
+	}, paths)
+
+	rslt, err = ts.Query("multiple_nested", "leaf")
+	assert.Nil(err)
+	assert.Equal(2, len(rslt))
+	assert.Equal(common.GetLineID("multiple_nested", "\t\tsubsubtask 0"), rslt[0].LineID)
+
+	_, err = ts.Query("nonexistent", "leaf")
+	assert.NotNil(err)
+	_, err = ts.Query("", "leaf and")
+	assert.NotNil(err)
+}
@@ -2,10 +2,15 @@ package server
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/danslimmon/impulse/analytics"
 	"github.com/danslimmon/impulse/common"
 )
 
@@ -13,69 +18,329 @@ import (
 type Taskstore interface {
 	GetTask(common.LineID) (*common.Task, error)
 
+	ListNames() ([]string, error)
 	GetList(string) ([]*common.Task, error)
+	// ResolveLine returns the ID of a line given its text as it appears in the list's data.
+	ResolveLine(string, string) (common.LineID, error)
 	PutList(string, []*common.Task) error
+	// CreateList creates a new, empty list.
+	CreateList(string) error
+	// ImportList adds tasks to the end of a list, creating the list if it doesn't exist.
+	ImportList(string, []*common.Task) error
+	// CheckList reports every problem with a list's data, and RepairList fixes those that can be
+	// fixed.
+	CheckList(string) (*CheckResult, error)
+	RepairList(string, string) error
 	InsertTask(common.LineID, *common.Task) error
+	// MoveToList moves a subtree to a position (interpreted as by InsertTask) in another list.
+	MoveToList(common.LineID, common.LineID) error
 	ArchiveLine(common.LineID) error
+	// ArchiveSubtree archives a line along with its descendants, rather than promoting them.
+	ArchiveSubtree(common.LineID) error
+	// GetNote and SetNote read and write the multi-line note attached to a node.
+	GetNote(common.LineID) (string, error)
+	SetNote(common.LineID, string) error
+	Unblock(common.LineID) error
+	SetMetadata(common.LineID, common.Metadata) (common.LineID, error)
+	Snooze(common.LineID, time.Time) (common.LineID, error)
+	// Search returns the nodes in all lists (and optionally the lines in the history file) that
+	// match a query.
+	Search(string, string, bool) ([]SearchResult, error)
+	// Query returns the nodes in a list (or in all lists) that match a query (see common.Query).
+	Query(string, string) ([]SearchResult, error)
+
+	// GetTimeIntervals returns the intervals during which nodes have been the top frame of their
+	// lists.
+	GetTimeIntervals() ([]TimeInterval, error)
+	// Interrupts returns the pushes that interrupted the frame on top of a list, rather than being
+	// planned substeps of it.
+	Interrupts() ([]analytics.Interrupt, error)
+	// Stats computes productivity statistics from the history file and the timelog.
+	Stats(time.Time) (*analytics.Stats, error)
+
+	StartFocus(string, time.Duration) (*FocusSession, error)
+	GetFocus() (*FocusSession, error)
+	StopFocus() error
+
+	// GetActiveList and SetActiveList read and write the name of the list that commands act on by
+	// default, and Stacks summarizes every list.
+	GetActiveList() (string, error)
+	SetActiveList(string) error
+	Stacks() ([]Stack, error)
+
+	// GetHoist, SetHoist, and Unhoist manage a client's zoom into a subtree, to which Push, Pop, and
+	// the client's notion of the top frame are scoped.
+	GetHoist(string) (*Hoist, *common.Task, error)
+	SetHoist(string, common.LineID) (*Hoist, error)
+	Unhoist(string) error
+	Push(string, string, *common.Task) (common.LineID, error)
+	Pop(string, string) ([]string, error)
+
+	// StaleNodes returns the nodes that haven't been touched in a while, and Touch marks a node as
+	// touched.
+	StaleNodes(string, time.Duration) ([]StaleNode, error)
+	Touch(common.LineID) error
+
+	AddRecurrence(string, string, *common.Task) (*Recurrence, error)
+	GetRecurrences(string) ([]*Recurrence, error)
+	SetRecurrencePaused(string, bool) error
+	DeleteRecurrence(string) error
+
+	// Feed returns the ChangeFeed to which the Taskstore publishes an event after each mutation.
+	Feed() *ChangeFeed
+	// Watch publishes events for changes made to the underlying data by other programs, until the
+	// given channel is closed.
+	Watch(<-chan struct{})
+	// Schedule reveals snoozed nodes and instantiates recurrences when their time comes, until the
+	// given channel is closed.
+	Schedule(<-chan struct{})
+}
+
+// StateID returns an identifier for the state of a task list whose marshaled data is b.
+//
+// Two lists have the same StateID if and only if their marshaled data is identical.
+func StateID(b []byte) string {
+	shasumArray := sha256.Sum256(b)
+	return hex.EncodeToString(shasumArray[:])
 }
 
 // BasicTaskstore is a Taskstore implementation in which trees are stored in a basic,
 // text-editor-centric serialization format.
 //
 // The basic format consists of a sequence of lines. A line that is not indented is a direct child
-// of the root node. A line that is indented (by any number of consecutive tab characters at the
+// of the root node. A line that is indented (by any number of consecutive indent units at the
 // beginning of the line) represents a direct child of the next line down with an indentation level
 // one less. The bottom line of a tree representation must not be indented.
 //
+// Characters in a referent that would otherwise change the structure of the data, such as tabs and
+// newlines, are escaped with backslashes (see escapeReferent).
+//
+// A node's note (see common.TreeNode's Note field) is written on the lines directly above the
+// node's line, with the same indentation. Each line of the note is prefixed with `| `, or is just
+// `|` if it's empty.
+//
+// A line whose text (after any indentation) is `#` or begins with `# ` is a comment, and a line
+// that consists of nothing but whitespace is blank. Comment and blank lines aren't nodes; they're
+// attached to the node whose line is next down (see common.TreeNode's Comments field), so that
+// they're written back where they were found. A comment's `#` must be followed by a space so that
+// it isn't mistaken for a tag.
+//
+// The indent unit is a tab, 2 spaces, or 4 spaces. It's detected separately for each file (see
+// detectIndent), and files are written back in the unit they already use. New files are written in
+// the Taskstore's default unit (see SetIndent).
+//
 // For examples, see treestore_test.go.
 type BasicTaskstore struct {
 	datastore Datastore
+	feed      *ChangeFeed
+	// indent is the indent unit in which new lists are written.
+	indent string
+
+	// tops caches the most recently recorded top frame path for each list in the timelog. It's
+	// loaded lazily by lastTops.
+	tops      map[string][]string
+	timelogMu sync.Mutex
+	// focusMu serializes access to the focus session.
+	focusMu sync.Mutex
+	// recurMu serializes access to the recurrences file.
+	recurMu sync.Mutex
+	// hoistMu serializes access to the hoists file.
+	hoistMu sync.Mutex
+	// touched caches the last-touched time of each node, by line ID. It's loaded lazily by
+	// lastTouched, and touchedMu serializes access to it.
+	touched   map[common.LineID]time.Time
+	touchedMu sync.Mutex
 }
 
-// parseLine parses a line of basic-format tree data.
+// SetIndent sets the indent unit (IndentTab, Indent2Spaces, or Indent4Spaces) in which ts writes
+// lists that don't already have one.
+func (ts *BasicTaskstore) SetIndent(unit string) error {
+	switch unit {
+	case IndentTab, Indent2Spaces, Indent4Spaces:
+		ts.indent = unit
+		return nil
+	}
+	return fmt.Errorf("invalid indent unit %q", unit)
+}
+
+// Feed returns the ChangeFeed to which ts publishes an event after each mutation.
+func (ts *BasicTaskstore) Feed() *ChangeFeed {
+	return ts.feed
+}
+
+// publish records in ts's ChangeFeed that the list identified by listName has been changed by op.
 //
-// It returns the integer number of tabs that occur at the beginning of the line (its indent level)
-// and the remaining text of the line as a string.
-func (ts *BasicTaskstore) parseLine(line []byte) (int, string) {
-	textBytes := bytes.TrimLeft(line, "\t")
-	indent := len(line) - len(textBytes)
-	return indent, string(textBytes)
+// b is the list's marshaled data after the change.
+func (ts *BasicTaskstore) publish(listName, op string, lineId common.LineID, b []byte) {
+	ts.feed.Publish(ChangeEvent{
+		ListName: listName,
+		Op:       op,
+		LineID:   lineId,
+		StateID:  StateID(b),
+	})
+	ts.observe(listName, lineId, b)
 }
 
-// derefLineId takes a line ID and determines the corresponding list name and line number.
+// observe updates the state that the Taskstore derives from the list identified by listName (the
+// timelog, the focus session, and the last-touched times) after the list has changed. b is the
+// list's marshaled data, and lineId identifies the line that the change was made to, if any.
 //
-// The line number returned is zero-indexed (the first line of the file is line 0).
-func (ts *BasicTaskstore) derefLineId(lineId common.LineID) (string, int, error) {
-	parts := strings.SplitN(string(lineId), ":", 2)
-	if len(parts) != 2 {
-		return "", 0, fmt.Errorf("malformatted line ID `%s`", string(lineId))
+// Failing to keep time shouldn't keep a change from going through, so callers are free to ignore
+// the returned error.
+func (ts *BasicTaskstore) observe(listName string, lineId common.LineID, b []byte) error {
+	taskList, err := ts.unmarshalList(listName, b)
+	if err != nil {
+		return err
+	}
+	if err := ts.trackTop(listName, taskList); err != nil {
+		return err
 	}
+	if err := ts.trackTouched(listName, taskList, lineId); err != nil {
+		return err
+	}
+	return ts.checkFocus(listName, taskList)
+}
 
-	listName := parts[0]
+// parseLine parses a line of basic-format tree data.
+//
+// It returns the integer number of indent units that occur at the beginning of the line (its indent
+// level) and the remaining text of the line as a string.
+func (ts *BasicTaskstore) parseLine(line []byte, unit string) (int, string) {
+	return splitIndent(string(line), unit)
+}
 
-	if parts[1] == "0" {
-		return listName, 0, nil
-	}
+// isCommentLine determines whether line, a line of basic-format data, is a comment or blank line
+// rather than a node.
+func isCommentLine(line string) bool {
+	text := strings.TrimSpace(line)
+	return text == "" || text == "#" || strings.HasPrefix(text, "# ")
+}
 
-	b, err := ts.datastore.Get(listName)
-	if err != nil {
-		return "", 0, err
+// splitLineId takes a line ID and returns the corresponding list name, along with the part of the
+// line ID that identifies a line within that list.
+func (ts *BasicTaskstore) splitLineId(lineId common.LineID) (string, string, error) {
+	parts := strings.SplitN(string(lineId), ":", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("malformatted line ID `%s`", string(lineId))
 	}
+	return parts[0], parts[1], nil
+}
 
+// findLine determines the line number of the line with the given ID in b, the marshaled data of
+// the list identified by listName.
+//
+// The line number returned is zero-indexed (the first line of the file is line 0).
+func (ts *BasicTaskstore) findLine(listName string, b []byte, lineId common.LineID) (int, error) {
+	unit := detectIndent(b, ts.indent)
 	lines := bytes.Split(b, []byte("\n"))
 	lineNo := -1
 	for n, line := range lines {
-		if common.GetLineID(listName, string(line)) == lineId {
+		if common.GetLineID(listName, canonicalLine(string(line), unit)) == lineId {
 			lineNo = n
 		}
 	}
 	if lineNo == -1 {
-		return "", 0, fmt.Errorf("no line with ID `%s`", string(lineId))
+		return 0, fmt.Errorf("no line with ID `%s`", string(lineId))
 	}
+	return lineNo, nil
+}
 
+// ResolveLine returns the ID of the line in the list identified by listName whose text, as it
+// appears in the list's data (indentation included, in whatever unit the file uses), is text.
+//
+// This is how clients should work out the ID of a line that the user has copied from a list file,
+// since line IDs are computed from canonical lines (see canonicalLine).
+func (ts *BasicTaskstore) ResolveLine(listName, text string) (common.LineID, error) {
+	b, err := ts.readList(listName)
+	if err != nil {
+		return "", err
+	}
+	unit := detectIndent(b, ts.indent)
+	lineId := common.GetLineID(listName, canonicalLine(strings.TrimRight(text, "\r\n"), unit))
+	if _, err := ts.findLine(listName, b, lineId); err != nil {
+		return "", fmt.Errorf("no line `%s` in list `%s`", text, listName)
+	}
+	return lineId, nil
+}
+
+// derefLineId takes a line ID and determines the corresponding list name and line number.
+//
+// The line number returned is zero-indexed (the first line of the file is line 0).
+func (ts *BasicTaskstore) derefLineId(lineId common.LineID) (string, int, error) {
+	listName, linePart, err := ts.splitLineId(lineId)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if linePart == "0" {
+		return listName, 0, nil
+	}
+
+	b, err := ts.readList(listName)
+	if err != nil {
+		return "", 0, err
+	}
+
+	lineNo, err := ts.findLine(listName, b, lineId)
+	if err != nil {
+		return "", 0, err
+	}
 	return listName, lineNo, nil
 }
 
+// nodeLineId returns the ID of the line representing n in the list identified by listName.
+func (ts *BasicTaskstore) nodeLineId(listName string, n *common.TreeNode) common.LineID {
+	return common.GetLineID(listName, strings.Repeat("\t", n.Depth())+escapeReferent(n.Referent))
+}
+
+// locateLine finds the line identified by lineId.
+//
+// It returns the name of the list containing the line, the list's marshaled data, and the line's
+// number within that data.
+func (ts *BasicTaskstore) locateLine(lineId common.LineID) (string, []byte, int, error) {
+	listName, linePart, err := ts.splitLineId(lineId)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	b, err := ts.readList(listName)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	if linePart == "0" {
+		return listName, b, 0, nil
+	}
+	lineNo, err := ts.findLine(listName, b, lineId)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	return listName, b, lineNo, nil
+}
+
+// replaceLine replaces the text of line lineNo in b, the marshaled data of the list identified by
+// listName, and writes the result to the Datastore. The line's indentation is preserved, and so are
+// any hoists that pass through the line's node (see followHoists).
+//
+// A change event attributed to op is published, with the ID of the line's new content.
+func (ts *BasicTaskstore) replaceLine(listName string, b []byte, lineNo int, text, op string) error {
+	baseStateId := StateID(b)
+	unit := detectIndent(b, ts.indent)
+	lines := bytes.Split(b, []byte("\n"))
+	oldId := common.GetLineID(listName, canonicalLine(string(lines[lineNo]), unit))
+	indent, _ := ts.parseLine(lines[lineNo], unit)
+	lines[lineNo] = []byte(strings.Repeat(unit, indent) + text)
+	newB := bytes.Join(lines, []byte("\n"))
+
+	if err := ts.datastore.CompareAndPut(listName, baseStateId, newB); err != nil {
+		return err
+	}
+	newId := common.GetLineID(listName, canonicalLine(strings.Repeat("\t", indent)+text, "\t"))
+	ts.followHoists(listName, b, oldId, newB, newId)
+	ts.publish(listName, op, newId, newB)
+	return nil
+}
+
 // GetTask returns the task at the given line ID.
 //
 // If no such task exists, GetTask returns an error.
@@ -87,30 +352,62 @@ func (ts *BasicTaskstore) GetTask(lineId common.LineID) (*common.Task, error) {
 
 	taskList, err := ts.GetList(taskListId)
 	for _, task := range taskList {
-		if lineId == common.GetLineID(taskListId, task.RootNode.Referent) {
+		if lineId == ts.nodeLineId(taskListId, task.RootNode) {
 			return task, nil
 		}
 	}
 	return nil, fmt.Errorf("Task '%s' not found", lineId)
 }
 
+// ListNames returns the names of all the task lists in the Datastore, in lexical order.
+func (ts *BasicTaskstore) ListNames() ([]string, error) {
+	names, err := ts.datastore.List()
+	if err != nil {
+		return nil, err
+	}
+
+	rslt := make([]string, 0, len(names))
+	for _, name := range names {
+		if ts.isListName(name) {
+			rslt = append(rslt, name)
+		}
+	}
+	return rslt, nil
+}
+
+// readList returns the marshaled data of the list identified by name. If there's no such list, the
+// error is a NoSuchListError.
+func (ts *BasicTaskstore) readList(name string) ([]byte, error) {
+	b, err := ts.datastore.Get(name)
+	if os.IsNotExist(err) {
+		return nil, &NoSuchListError{ListName: name}
+	}
+	return b, err
+}
+
 // Get retrieves the task list with the given name from the persistent Datastore.
+//
+// An empty list is returned as an empty slice. If there's no list with the given name, the error
+// is a NoSuchListError.
 func (ts *BasicTaskstore) GetList(name string) ([]*common.Task, error) {
-	b, err := ts.datastore.Get(name)
+	b, err := ts.readList(name)
 	if err != nil {
 		return nil, err
 	}
+	return ts.unmarshalList(name, b)
+}
+
+// unmarshalList parses b, the basic-format data of the task list identified by name.
+//
+// Empty data is an empty list.
+func (ts *BasicTaskstore) unmarshalList(name string, b []byte) ([]*common.Task, error) {
 	if len(b) == 0 {
-		return nil, fmt.Errorf("data for tree '%s' is zero-length", name)
+		return []*common.Task{}, nil
 	}
 
 	// lines ends up just being splut in reverse. so lines is all the lines in the file, from the
 	// bottom to the top of the file. that's how we want it for constructing the tree further down.
 	splut := bytes.Split(b, []byte("\n"))
-	if len(splut) < 2 {
-		return []*common.Task{}, nil
-	}
-
 	nLines := len(splut)
 	lines := make([][]byte, nLines)
 	for i := range splut {
@@ -121,7 +418,7 @@ func (ts *BasicTaskstore) GetList(name string) ([]*common.Task, error) {
 		lines = lines[1:]
 		nLines = nLines - 1
 	} else {
-		return nil, fmt.Errorf("data for tree '%s' does not end in newline", name)
+		return nil, &ParseError{ListName: name, Err: ErrNoTrailingNewline}
 	}
 
 	rootNode := common.NewTreeNode("")
@@ -133,10 +430,35 @@ func (ts *BasicTaskstore) GetList(name string) ([]*common.Task, error) {
 	prevIndent := -1
 	// prevNode points to the line parsed in the previous iteration of the loop.
 	prevNode := rootNode
+	unit := detectIndent(b, ts.indent)
+	// comments holds the comment and blank lines seen since the previous node, in the order in which
+	// they appear in the file.
+	comments := make([]string, 0)
+	// notes holds the lines of the previous node's note seen so far, in the order in which they
+	// appear in the file.
+	notes := make([]string, 0)
 	for i, line := range lines {
-		indent, text := ts.parseLine(line)
+		if isNoteLine(string(line)) && prevNode != rootNode {
+			notes = append([]string{noteText(string(line))}, notes...)
+			prevNode.Note = strings.Join(notes, "\n")
+			continue
+		}
+		if isCommentLine(string(line)) || isNoteLine(string(line)) {
+			comments = append([]string{string(line)}, comments...)
+			continue
+		}
+		indent, text := ts.parseLine(line, unit)
 		deltaIndent := indent - prevIndent
-		newNode := common.NewTreeNode(text)
+		newNode := common.NewTreeNode(unescapeReferent(text))
+		notes = make([]string, 0)
+		if len(comments) > 0 {
+			if prevNode == rootNode {
+				newNode.TrailingComments = comments
+			} else {
+				prevNode.Comments = comments
+			}
+			comments = make([]string, 0)
+		}
 
 		if deltaIndent == 1 {
 			// this is a child of the previous node
@@ -153,16 +475,22 @@ func (ts *BasicTaskstore) GetList(name string) ([]*common.Task, error) {
 			}
 			ancestorNode.InsertChild(0, newNode)
 		} else {
-			return nil, fmt.Errorf(
-				"error parsing line %d of tree '%s': unexpected deltaIndent = %d",
-				i,
-				name,
-				deltaIndent,
-			)
+			return nil, &IndentError{
+				ListName: name,
+				// lines is bottom-up, but line numbers are counted from the top.
+				Line:      nLines - i,
+				Column:    indent + 1,
+				Text:      string(line),
+				MaxIndent: prevIndent + 1,
+			}
 		}
 		prevNode = newNode
 		prevIndent = indent
 	}
+	// Whatever comments are left are at the top of the file.
+	if len(comments) > 0 && prevNode != rootNode {
+		prevNode.Comments = comments
+	}
 
 	// Now we take all the children of rootNode and load them into the list to be returned.
 	rslt := make([]*common.Task, 0)
@@ -173,95 +501,380 @@ func (ts *BasicTaskstore) GetList(name string) ([]*common.Task, error) {
 	return rslt, nil
 }
 
-// Put writes taskList to the Datastore as name.
-func (ts *BasicTaskstore) PutList(name string, taskList []*common.Task) error {
+// marshalList returns the basic-format representation of taskList, indented with unit.
+//
+// Referents are escaped (see escapeReferent), each node's note is written above its line, and each
+// node's comments are written around it, where unmarshalList found them.
+func (ts *BasicTaskstore) marshalList(taskList []*common.Task, unit string) []byte {
 	b := []byte{}
 	for _, t := range taskList {
 		t.RootNode.WalkFromTop(func(n *common.TreeNode) error {
-			b = append(b, bytes.Repeat([]byte("\t"), n.Depth())...)
-			b = append(b, []byte(n.Referent)...)
+			for _, c := range n.Comments {
+				b = append(b, []byte(c+"\n")...)
+			}
+			for _, l := range noteLines(n.Note, strings.Repeat(unit, n.Depth())) {
+				b = append(b, []byte(l+"\n")...)
+			}
+			b = append(b, []byte(strings.Repeat(unit, n.Depth()))...)
+			b = append(b, []byte(escapeReferent(n.Referent))...)
 			b = append(b, []byte("\n")...)
+			for _, c := range n.TrailingComments {
+				b = append(b, []byte(c+"\n")...)
+			}
 			return nil
 		})
 	}
-	return ts.datastore.Put(name, b)
+	return b
+}
+
+// Put writes taskList to the Datastore as name.
+func (ts *BasicTaskstore) PutList(name string, taskList []*common.Task) error {
+	return ts.putList(name, taskList, "", "PutList", "")
+}
+
+// CreateList creates a new, empty list called name. It's an error if the list already exists.
+func (ts *BasicTaskstore) CreateList(name string) error {
+	if !ts.isListName(name) {
+		return fmt.Errorf("`%s` is not a task list", name)
+	}
+	if err := ts.datastore.Create(name); err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("list `%s` already exists", name)
+		}
+		return err
+	}
+	ts.publish(name, "CreateList", "", []byte{})
+	return nil
+}
+
+// ImportList adds taskList to the end of the list identified by name. If there's no such list,
+// it's created.
+func (ts *BasicTaskstore) ImportList(name string, taskList []*common.Task) error {
+	if !ts.isListName(name) {
+		return fmt.Errorf("`%s` is not a task list", name)
+	}
+	if len(taskList) == 0 {
+		return fmt.Errorf("no tasks to import")
+	}
+
+	b, err := ts.datastore.Get(name)
+	if os.IsNotExist(err) {
+		return ts.putList(name, taskList, "", "ImportList", "")
+	}
+	if err != nil {
+		return err
+	}
+	existing, err := ts.unmarshalList(name, b)
+	if err != nil {
+		return err
+	}
+	return ts.putList(name, append(existing, taskList...), StateID(b), "ImportList", "")
+}
+
+// putList writes taskList to the Datastore as name, and publishes a change event attributed to op
+// and lineId.
+//
+// If baseStateId is not empty, the write is refused with ErrStaleWrite unless the list's current
+// StateID is baseStateId. This keeps us from clobbering changes made (e.g. in a text editor)
+// between when we read the list and when we write it back.
+//
+// If the list already exists, it's written in the indent unit it's already written in.
+func (ts *BasicTaskstore) putList(name string, taskList []*common.Task, baseStateId, op string, lineId common.LineID) error {
+	unit := ts.indent
+	if cur, err := ts.datastore.Get(name); err == nil {
+		unit = detectIndent(cur, ts.indent)
+	}
+	b := ts.marshalList(taskList, unit)
+	var err error
+	if baseStateId == "" {
+		err = ts.datastore.Put(name, b)
+	} else {
+		err = ts.datastore.CompareAndPut(name, baseStateId, b)
+	}
+	if err != nil {
+		return err
+	}
+	ts.publish(name, op, lineId, b)
+	return nil
 }
 
 // InsertTask inserts the given task after the given position.
 //
 // See common.LineID docs for information about how position is interpreted.
 func (ts *BasicTaskstore) InsertTask(lineId common.LineID, task *common.Task) error {
-	listName, lineNo, err := ts.derefLineId(lineId)
+	listName, linePart, err := ts.splitLineId(lineId)
 	if err != nil {
 		return err
 	}
+	if err := validateTask(task); err != nil {
+		return err
+	}
 
-	taskList, err := ts.GetList(listName)
+	b, err := ts.readList(listName)
+	if err != nil {
+		return err
+	}
+	if linePart != "0" {
+		if _, err := ts.findLine(listName, b, lineId); err != nil {
+			return err
+		}
+	}
+
+	taskList, err := ts.unmarshalList(listName, b)
 	if err != nil {
 		return err
 	}
 
-	if lineNo == 0 {
-		taskList = append([]*common.Task{task}, taskList...)
-		return ts.PutList(listName, taskList)
+	prevTop := common.Top(taskList)
+	taskList, err = ts.insertIntoList(listName, taskList, lineId, task)
+	if err != nil {
+		return err
+	}
+	insertedId := ts.nodeLineId(listName, task.RootNode)
+	if err := ts.putList(listName, taskList, StateID(b), "InsertTask", insertedId); err != nil {
+		return err
+	}
+	ts.appendHistory(pushHistoryLine(listName, task.RootNode, time.Now()))
+	ts.recordInterrupt(listName, prevTop, taskList, task.RootNode)
+	return nil
+}
+
+// insertIntoList returns taskList, the list identified by listName, with task inserted at the
+// position identified by lineId, as interpreted by InsertTask.
+func (ts *BasicTaskstore) insertIntoList(listName string, taskList []*common.Task, lineId common.LineID, task *common.Task) ([]*common.Task, error) {
+	if _, linePart, _ := ts.splitLineId(lineId); linePart == "0" {
+		return append([]*common.Task{task}, taskList...), nil
 	}
 
 	for i := range taskList {
-		if common.GetLineID(listName, taskList[i].RootNode.Referent) == lineId {
+		if ts.nodeLineId(listName, taskList[i].RootNode) == lineId {
 			if i+1 == len(taskList) {
-				return ts.PutList(listName, append(taskList, task))
+				return append(taskList, task), nil
 			}
 			taskList = append(taskList[:i+1], taskList[i:]...)
 			taskList[i] = task
-			return ts.PutList(listName, taskList)
+			return taskList, nil
 		}
 	}
 
-	return fmt.Errorf("no line exists with ID '%s'", lineId)
+	return nil, fmt.Errorf("no line exists with ID '%s'", lineId)
 }
 
-// historyLine returns a line for the history file based on the given line from an impulse file.
+// historyLine returns a line for the history file recording that b, a line from the list
+// identified by listName, was archived.
 //
 // History lines are of the form:
 //
-//     2021-12-30T19:24:48 [full contents of b, including any leading whitespace]
-func (ts *BasicTaskstore) historyLine(b []byte) []byte {
+//     2021-12-30T19:24:48 [archive make_pasta] [full contents of b, including any leading whitespace]
+//
+// Lines written before the list name was recorded lack the `[archive ...]` tag, and the trailing
+// newline (see splitHistory).
+func (ts *BasicTaskstore) historyLine(listName string, b []byte) []byte {
 	now := time.Now()
 	// make sure this is UTC before using it ^
-	timestamp := now.Format("2006-01-02T15:04:05")
-	return []byte(fmt.Sprintf("%s %s", timestamp, b))
+	timestamp := now.Format(historyTimeFormat)
+	return []byte(fmt.Sprintf("%s [archive %s] %s\n", timestamp, listName, b))
 }
 
 // ArchiveLine archives the line identified by lineId.
 //
-// lineId may refer either to a subtask or a task proper.
+// lineId may refer either to a subtask or a task proper. If the line has subtasks, they're promoted
+// to take its place. The line's note, if any, is removed along with it.
 func (ts *BasicTaskstore) ArchiveLine(lineId common.LineID) error {
-	listName, lineNo, err := ts.derefLineId(lineId)
+	listName, b, lineNo, err := ts.locateLine(lineId)
+	if err != nil {
+		return err
+	}
+	baseStateId := StateID(b)
+
+	lines := bytes.Split(b, []byte("\n"))
+	// Will panic on index-out-of-range, but it should. That means there's a bug in findLine.
+	removedLine := make([]byte, len(lines[lineNo]))
+	copy(removedLine, lines[lineNo])
+
+	// The line's descendants are the lines above its note that are indented further. Outdent them
+	// by one level, so that they don't end up under whatever line is below.
+	unit := detectIndent(b, ts.indent)
+	indent, _ := ts.parseLine(lines[lineNo], unit)
+	noteStart, _ := readNote(lines, lineNo)
+	for i := noteStart - 1; i >= 0; i-- {
+		if isCommentLine(string(lines[i])) || isNoteLine(string(lines[i])) {
+			continue
+		}
+		descIndent, text := ts.parseLine(lines[i], unit)
+		if descIndent <= indent {
+			break
+		}
+		lines[i] = []byte(strings.Repeat(unit, descIndent-1) + text)
+	}
+
+	// Since b ends in a newline, the last element of lines is empty, and it's still there to end
+	// the file in a newline after we remove the line. If we remove the only line, b becomes empty.
+	lines = append(lines[0:noteStart], lines[lineNo+1:]...)
+	b = bytes.Join(lines, []byte("\n"))
+
+	if err := ts.datastore.CompareAndPut(listName, baseStateId, b); err != nil {
+		return err
+	}
+	ts.appendHistory(ts.historyLine(listName, removedLine))
+	ts.publish(listName, "ArchiveLine", lineId, b)
+	return nil
+}
+
+// ArchiveSubtree archives the line identified by lineId along with all its descendants, whereas
+// ArchiveLine would promote the descendants to take the line's place.
+func (ts *BasicTaskstore) ArchiveSubtree(lineId common.LineID) error {
+	listName, _, err := ts.splitLineId(lineId)
 	if err != nil {
 		return err
 	}
+	taskList, err := ts.GetList(listName)
+	if err != nil {
+		return err
+	}
+	n := ts.findNode(listName, taskList, lineId)
+	if n == nil {
+		return fmt.Errorf("no node with ID `%s`", string(lineId))
+	}
+
+	// Archive children before their parents, so that by the time each line is archived it has no
+	// descendants left to promote. Archiving a line doesn't change the IDs of the other lines, so
+	// the IDs can all be worked out up front.
+	ids := make([]common.LineID, 0)
+	var collect func(n *common.TreeNode)
+	collect = func(n *common.TreeNode) {
+		for _, ch := range n.Children {
+			collect(ch)
+		}
+		ids = append(ids, ts.nodeLineId(listName, n))
+	}
+	collect(n)
+	for _, id := range ids {
+		if err := ts.ArchiveLine(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	b, err := ts.datastore.Get(listName)
+// Unblock removes the blocker annotations (see common.Blocker) from the line identified by lineId,
+// indicating that whatever the line was waiting on has come to pass.
+//
+// If the line consists of nothing but blocker annotations (e.g. `[b cooked]`), it's archived.
+func (ts *BasicTaskstore) Unblock(lineId common.LineID) error {
+	listName, b, lineNo, err := ts.locateLine(lineId)
 	if err != nil {
 		return err
 	}
 
+	_, text := ts.parseLine(bytes.Split(b, []byte("\n"))[lineNo], detectIndent(b, ts.indent))
+	blockers, rest := common.ParseBlockers(text)
+	if len(blockers) == 0 {
+		return fmt.Errorf("line `%s` is not blocked", string(lineId))
+	}
+	if rest == "" {
+		return ts.ArchiveLine(lineId)
+	}
+	return ts.replaceLine(listName, b, lineNo, rest, "Unblock")
+}
+
+// SetMetadata replaces the metadata (see common.Metadata) of the line identified by lineId with
+// md.
+//
+// Since a line's ID depends on its content, this changes the line's ID. The new ID is returned.
+func (ts *BasicTaskstore) SetMetadata(lineId common.LineID, md common.Metadata) (common.LineID, error) {
+	return ts.editMetadata(lineId, "SetMetadata", func(common.Metadata) common.Metadata {
+		return md
+	})
+}
+
+// Snooze hides the line identified by lineId from the stack until the given time, by setting its
+// NotBefore metadata.
+//
+// Like SetMetadata, Snooze returns the line's new ID.
+func (ts *BasicTaskstore) Snooze(lineId common.LineID, until time.Time) (common.LineID, error) {
+	// not-before times are only precise to the minute, so round up rather than reveal the line
+	// early
+	if t := until.Truncate(time.Minute); t.Before(until) {
+		until = t.Add(time.Minute)
+	}
+	return ts.editMetadata(lineId, "Snooze", func(md common.Metadata) common.Metadata {
+		md.NotBefore = &until
+		return md
+	})
+}
+
+// editMetadata replaces the metadata of the line identified by lineId with the result of calling
+// fn on its current metadata. A change event attributed to op is published.
+//
+// The line's new ID is returned.
+func (ts *BasicTaskstore) editMetadata(lineId common.LineID, op string, fn func(common.Metadata) common.Metadata) (common.LineID, error) {
+	listName, b, lineNo, err := ts.locateLine(lineId)
+	if err != nil {
+		return "", err
+	}
+
 	lines := bytes.Split(b, []byte("\n"))
-	// Will panic on index-out-of-range, but it should. That means there's a bug in derefLineId.
-	removedLine := make([]byte, len(lines[lineNo]))
-	copy(removedLine, lines[lineNo])
+	indent, text := ts.parseLine(lines[lineNo], detectIndent(b, ts.indent))
+	md, text := common.ParseMetadata(text)
+	text = common.FormatMetadata(text, fn(md))
+	if err := ts.replaceLine(listName, b, lineNo, text, op); err != nil {
+		return "", err
+	}
+	return common.GetLineID(listName, canonicalLine(strings.Repeat("\t", indent)+text, "\t")), nil
+}
 
-	lines = append(lines[0:lineNo], lines[lineNo+1:]...)
-	// Add empty line so that file will end with a newline
-	lines = append(lines, []byte{})
-	b = bytes.Join(lines, []byte("\n"))
+// nonListNames are the names of the files in the Datastore that don't hold task lists.
+var nonListNames = map[string]bool{
+	"history":       true,
+	timelogName:     true,
+	focusName:       true,
+	recurrencesName: true,
+	activeName:      true,
+	hoistsName:      true,
+	touchedName:     true,
+}
 
-	ts.datastore.Append("history", ts.historyLine(removedLine))
+// isListName determines whether the file with the given name in the Datastore holds a task list, as
+// opposed to other data such as the history file.
+func (ts *BasicTaskstore) isListName(name string) bool {
+	return !nonListNames[name]
+}
+
+// Watch watches the Datastore for changes made by other programs (e.g. somebody editing a list in
+// their text editor) until stop is closed.
+//
+// Each changed list is validated, and an "ExternalEdit" event is published to ts's ChangeFeed. If
+// the list can no longer be parsed, the event's Error field says why.
+func (ts *BasicTaskstore) Watch(stop <-chan struct{}) {
+	for name := range ts.datastore.Watch(stop) {
+		if !ts.isListName(name) {
+			continue
+		}
 
-	return ts.datastore.Put(listName, b)
+		ev := ChangeEvent{
+			ListName: name,
+			Op:       "ExternalEdit",
+		}
+		b, err := ts.datastore.Get(name)
+		if err == nil {
+			ev.StateID = StateID(b)
+			_, err = ts.unmarshalList(name, b)
+		}
+		if err != nil {
+			ev.Error = err.Error()
+		} else {
+			ts.observe(name, "", b)
+		}
+		ts.feed.Publish(ev)
+	}
 }
 
 // NewBasicTaskstore returns a BasicTaskstore with the given underlying datastore.
 func NewBasicTaskstore(datastore Datastore) *BasicTaskstore {
-	return &BasicTaskstore{datastore: datastore}
+	return &BasicTaskstore{
+		datastore: datastore,
+		feed:      NewChangeFeed(),
+		indent:    IndentTab,
+	}
 }
@@ -1,8 +1,12 @@
 package server
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -138,8 +142,8 @@ func TestBasicTaskstore_GetList_Malformed(t *testing.T) {
 	ts := NewBasicTaskstore(ds)
 
 	paths := []string{
-		"malformed/zero_length",
 		"malformed/excess_delta_indent",
+		"malformed/no_trailing_newline",
 		"malformed/missing",
 	}
 	for _, p := range paths {
@@ -151,6 +155,50 @@ func TestBasicTaskstore_GetList_Malformed(t *testing.T) {
 	}
 }
 
+func TestBasicTaskstore_GetList_Empty(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ts, cleanup := NewBasicTaskstoreWithTestdata()
+	defer cleanup()
+
+	taskList, err := ts.GetList("empty")
+	assert.Nil(err)
+	assert.NotNil(taskList)
+	assert.Equal(0, len(taskList))
+
+	_, err = ts.GetList("nonexistent")
+	assert.IsType(&NoSuchListError{}, err)
+}
+
+func TestBasicTaskstore_CreateList(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ts, cleanup := NewBasicTaskstoreWithTestdata()
+	defer cleanup()
+
+	err := ts.CreateList("groceries")
+	assert.Nil(err)
+	taskList, err := ts.GetList("groceries")
+	assert.Nil(err)
+	assert.Equal(0, len(taskList))
+	evs := ts.Feed().Since("groceries", 0, 0)
+	assert.Equal(1, len(evs))
+	assert.Equal("CreateList", evs[0].Op)
+
+	// A task can be inserted at the top of the new list
+	err = ts.InsertTask(common.LineID("groceries:0"), common.NewTask(common.NewTreeNode("eggs")))
+	assert.Nil(err)
+	taskList, err = ts.GetList("groceries")
+	assert.Nil(err)
+	assert.Equal(1, len(taskList))
+
+	assert.NotNil(ts.CreateList("groceries"))
+	assert.NotNil(ts.CreateList("empty"))
+	assert.NotNil(ts.CreateList("history"))
+}
+
 func TestBasicTaskstore_PutList(t *testing.T) {
 	t.Parallel()
 	assert := assert.New(t)
@@ -175,6 +223,116 @@ func TestBasicTaskstore_PutList(t *testing.T) {
 	assert.True(taskList[1].RootNode.Equal(b))
 }
 
+// Tests that lists indented with spaces are read, and written back with the same indentation.
+func TestBasicTaskstore_SpaceIndent(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	a := common.NewTreeNode("make pasta")
+	a.AddChild(common.NewTreeNode("boil water"))
+	a.Children[0].AddChild(common.NewTreeNode("put water in pot"))
+
+	err := ds.Put("spaces", []byte("    put water in pot\n  boil water\nmake pasta\n"))
+	assert.Nil(err)
+	taskList, err := ts.GetList("spaces")
+	assert.Nil(err)
+	assert.Equal(1, len(taskList))
+	assert.True(a.Equal(taskList[0].RootNode))
+
+	// Line IDs don't depend on the indent unit.
+	_, err = ts.SetMetadata(
+		common.GetLineID("spaces", "\tboil water"),
+		common.Metadata{Tags: []string{"kitchen"}},
+	)
+	assert.Nil(err)
+	err = ts.InsertTask(common.LineID("spaces:0"), common.NewTask(common.NewTreeNode("drain pasta")))
+	assert.Nil(err)
+	b, err := ds.Get("spaces")
+	assert.Nil(err)
+	assert.Equal("drain pasta\n    put water in pot\n  boil water #kitchen\nmake pasta\n", string(b))
+
+	// New lists are written in the default unit.
+	assert.NotNil(ts.SetIndent("   "))
+	assert.Nil(ts.SetIndent(Indent4Spaces))
+	err = ts.PutList("foo", []*common.Task{common.NewTask(a)})
+	assert.Nil(err)
+	b, err = ds.Get("foo")
+	assert.Nil(err)
+	assert.Equal("        put water in pot\n    boil water\nmake pasta\n", string(b))
+}
+
+// Tests that comment and blank lines survive a round trip through GetList and PutList.
+func TestBasicTaskstore_Comments(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	data := "# dinner\n\t\tput water in pot\n\t# the big pot\n\tboil water\n\n#work\n# lunch\nmake sandwich\n\n# eof\n"
+	err := ds.Put("commented", []byte(data))
+	assert.Nil(err)
+
+	taskList, err := ts.GetList("commented")
+	assert.Nil(err)
+	assert.Equal(2, len(taskList))
+	// `#work` is a tag, not a comment
+	assert.Equal("#work", taskList[0].RootNode.Referent)
+	boilWater := taskList[0].RootNode.Children[0]
+	assert.Equal([]string{"\t# the big pot"}, boilWater.Comments)
+	assert.Equal([]string{"# dinner"}, boilWater.Children[0].Comments)
+	assert.Equal([]string{""}, taskList[0].RootNode.Comments)
+	assert.Equal([]string{"# lunch"}, taskList[1].RootNode.Comments)
+	assert.Equal([]string{"", "# eof"}, taskList[1].RootNode.TrailingComments)
+
+	err = ts.PutList("commented", taskList)
+	assert.Nil(err)
+	b, err := ds.Get("commented")
+	assert.Nil(err)
+	assert.Equal(data, string(b))
+}
+
+func TestBasicTaskstore_ImportList(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	a := common.NewTreeNode("alpha")
+	a.AddChild(common.NewTreeNode("zulu"))
+	b := common.NewTreeNode("bravo")
+
+	ts, cleanup := NewBasicTaskstoreWithTestdata()
+	defer cleanup()
+
+	// New list
+	err := ts.ImportList("foo", []*common.Task{common.NewTask(a)})
+	assert.Nil(err)
+	taskList, err := ts.GetList("foo")
+	assert.Nil(err)
+	assert.Equal(1, len(taskList))
+	assert.True(taskList[0].RootNode.Equal(a))
+
+	// Existing list
+	err = ts.ImportList("make_pasta", []*common.Task{common.NewTask(b)})
+	assert.Nil(err)
+	taskList, err = ts.GetList("make_pasta")
+	assert.Nil(err)
+	assert.Equal(2, len(taskList))
+	assert.True(common.MakePasta()[0].Equal(taskList[0]))
+	assert.True(taskList[1].RootNode.Equal(b))
+
+	err = ts.ImportList("bar", []*common.Task{})
+	assert.NotNil(err)
+	err = ts.ImportList("history", []*common.Task{common.NewTask(b)})
+	assert.NotNil(err)
+	err = ts.ImportList("malformed/excess_delta_indent", []*common.Task{common.NewTask(b)})
+	assert.NotNil(err)
+}
+
 func TestBasicTaskstore_InsertTask(t *testing.T) {
 	t.Parallel()
 	assert := assert.New(t)
@@ -204,6 +362,75 @@ func TestBasicTaskstore_InsertTask(t *testing.T) {
 	assert.True(b.Equal(taskList[2].RootNode))
 }
 
+// Tests that referents containing tabs and newlines survive a round trip through the basic format.
+func TestBasicTaskstore_InsertTask_Escaping(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	a := common.NewTreeNode("\tbuy:\n- eggs\n- flour")
+	a.AddChild(common.NewTreeNode("# of eggs: 6"))
+	a.AddChild(common.NewTreeNode(" go to the store"))
+	err := ts.InsertTask(common.LineID("make_pasta:0"), common.NewTask(a))
+	assert.Nil(err)
+
+	b, err := ds.Get("make_pasta")
+	assert.Nil(err)
+	exp := "\t\\# of eggs: 6\n\t\\ go to the store\n\\tbuy:\\n- eggs\\n- flour\n"
+	assert.Equal(exp, string(b)[:len(exp)])
+
+	taskList, err := ts.GetList("make_pasta")
+	assert.Nil(err)
+	assert.Equal(2, len(taskList))
+	assert.True(a.Equal(taskList[0].RootNode))
+	assert.True(common.MakePasta()[0].Equal(taskList[1]))
+
+	// The line IDs of escaped lines can be found
+	task, err := ts.GetTask(common.GetLineID("make_pasta", `\tbuy:\n- eggs\n- flour`))
+	assert.Nil(err)
+	assert.True(a.Equal(task.RootNode))
+	err = ts.ArchiveLine(common.GetLineID("make_pasta", "\t\\# of eggs: 6"))
+	assert.Nil(err)
+
+	// Empty referents can't be represented
+	err = ts.InsertTask(common.LineID("make_pasta:0"), common.NewTask(common.NewTreeNode("")))
+	assert.NotNil(err)
+}
+
+// Tests that hand-written lines that aren't escaped the way we'd write them can be found by the IDs
+// of their nodes.
+func TestBasicTaskstore_HandWrittenEscaping(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	err := ds.Put("paths", []byte("back up C:\\path\n\tclean out C:\\work\nmaintenance\n"))
+	assert.Nil(err)
+	taskList, err := ts.GetList("paths")
+	assert.Nil(err)
+	root := taskList[0].RootNode
+	assert.Equal(`back up C:\path`, root.Referent)
+	child := taskList[1].RootNode.Children[0]
+	assert.Equal(`clean out C:\work`, child.Referent)
+
+	task, err := ts.GetTask(ts.nodeLineId("paths", root))
+	if assert.Nil(err) {
+		assert.True(root.Equal(task.RootNode))
+	}
+	lineId := ts.nodeLineId("paths", child)
+	assert.Nil(ts.Touch(lineId))
+	assert.Nil(ts.ArchiveLine(lineId))
+	b, err := ds.Get("paths")
+	assert.Nil(err)
+	assert.Equal("back up C:\\path\nmaintenance\n", string(b))
+}
+
 // Tests that ArchiveLine works when given an ID that corresponds to a subtask.
 func TestBasicTaskstore_ArchiveLine_Subtask(t *testing.T) {
 	t.Parallel()
@@ -228,7 +455,7 @@ func TestBasicTaskstore_ArchiveLine_Subtask(t *testing.T) {
 	// make sure that the history file now contains the line we archived
 	b, err = ds.Get("history")
 	assert.Nil(err)
-	assert.True(regexp.MustCompile("^[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]T[0-9][0-9]:[0-9][0-9]:[0-9][0-9] \t\tput water in pot$").Match(b))
+	assert.True(regexp.MustCompile("^[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]T[0-9][0-9]:[0-9][0-9]:[0-9][0-9] \\[archive make_pasta\\] \t\tput water in pot\n$").Match(b))
 }
 
 // Tests that ArchiveLine works when given an ID that corresponds to a task.
@@ -255,5 +482,203 @@ func TestBasicTaskstore_ArchiveLine_Task(t *testing.T) {
 	// make sure that the history file now contains the line we archived
 	b, err = ds.Get("history")
 	assert.Nil(err)
-	assert.True(regexp.MustCompile("^[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]T[0-9][0-9]:[0-9][0-9]:[0-9][0-9] make pasta$").Match(b))
+	assert.True(regexp.MustCompile("^[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]T[0-9][0-9]:[0-9][0-9]:[0-9][0-9] \\[archive make_pasta\\] make pasta\n$").Match(b))
+}
+
+// Tests that archiving every line leaves an empty list, rather than a malformed one.
+func TestBasicTaskstore_ArchiveLine_Last(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	ts := NewBasicTaskstore(ds)
+	defer cleanup()
+
+	err := ts.ArchiveLine(common.GetLineID("multiple_nested", "\t\tsubsubtask 0"))
+	assert.Nil(err)
+	b, err := ds.Get("multiple_nested")
+	assert.Nil(err)
+	assert.Equal("\tsubtask 0\ntask 0\n\tsubtask 1\ntask 1\n", string(b))
+
+	for _, line := range []string{"\tsubtask 0", "task 0", "\tsubtask 1", "task 1"} {
+		err = ts.ArchiveLine(common.GetLineID("multiple_nested", line))
+		assert.Nil(err)
+	}
+	b, err = ds.Get("multiple_nested")
+	assert.Nil(err)
+	assert.Equal("", string(b))
+	taskList, err := ts.GetList("multiple_nested")
+	assert.Nil(err)
+	assert.Equal(0, len(taskList))
+}
+
+// Tests that ArchiveLine promotes the subtasks of the line it archives.
+func TestBasicTaskstore_ArchiveLine_Parent(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	ts := NewBasicTaskstore(ds)
+	defer cleanup()
+
+	err := ts.ArchiveLine(common.GetLineID("make_pasta", "\tboil water"))
+	assert.Nil(err)
+
+	taskList, err := ts.GetList("make_pasta")
+	assert.Nil(err)
+	children := make([]string, 0)
+	for _, n := range taskList[0].RootNode.Children {
+		children = append(children, n.Referent)
+	}
+	assert.Equal([]string{
+		"put water in pot",
+		"put pot on burner",
+		"turn burner on",
+		"put pasta in water",
+		"[b cooked]",
+		"drain pasta",
+	}, children)
+}
+
+// Tests that ArchiveSubtree archives a line's descendants along with it.
+func TestBasicTaskstore_ArchiveSubtree(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	ts := NewBasicTaskstore(ds)
+	defer cleanup()
+
+	err := ts.ArchiveSubtree(common.GetLineID("make_pasta", "\tboil water"))
+	assert.Nil(err)
+
+	taskList, err := ts.GetList("make_pasta")
+	assert.Nil(err)
+	children := make([]string, 0)
+	for _, n := range taskList[0].RootNode.Children {
+		children = append(children, n.Referent)
+	}
+	assert.Equal([]string{
+		"put pasta in water",
+		"[b cooked]",
+		"drain pasta",
+	}, children)
+
+	// Each line is recorded in the history file
+	b, err := ds.Get("history")
+	assert.Nil(err)
+	assert.Equal(4, strings.Count(string(b), "[archive make_pasta]"))
+
+	err = ts.ArchiveSubtree(common.GetLineID("make_pasta", "\tboil water"))
+	assert.NotNil(err)
+}
+
+// Tests that Watch publishes events for lists edited by other programs.
+func TestBasicTaskstore_Watch(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ds.pollInterval = 10 * time.Millisecond
+	ts := NewBasicTaskstore(ds)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go ts.Watch(stop)
+	// give Watch a chance to take stock of the existing files
+	time.Sleep(50 * time.Millisecond)
+
+	// A valid edit
+	b := []byte("\tsubtask\ntask\n")
+	err := ioutil.WriteFile(filepath.Join(ds.rootDir, "multiple_nested"), b, 0644)
+	assert.Nil(err)
+	evs := ts.Feed().Since("multiple_nested", 0, 5*time.Second)
+	assert.Equal(1, len(evs))
+	assert.Equal("ExternalEdit", evs[0].Op)
+	assert.Equal(StateID(b), evs[0].StateID)
+	assert.Equal("", evs[0].Error)
+
+	// An edit that leaves the list malformed
+	err = ioutil.WriteFile(filepath.Join(ds.rootDir, "make_pasta"), []byte("\t\toops\nmake pasta\n"), 0644)
+	assert.Nil(err)
+	evs = ts.Feed().Since("make_pasta", 0, 5*time.Second)
+	assert.Equal(1, len(evs))
+	assert.Equal("ExternalEdit", evs[0].Op)
+	assert.NotEqual("", evs[0].Error)
+}
+
+// Tests that ArchiveLine refuses to overwrite changes made since the list was read.
+func TestBasicTaskstore_ArchiveLine_Stale(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(&racingDatastore{Datastore: ds})
+
+	err := ts.ArchiveLine(common.GetLineID("make_pasta", "\t\tput water in pot"))
+	assert.Equal(ErrStaleWrite, err)
+
+	// the edit that won the race should be intact, and nothing should have been archived
+	b, err := ds.Get("make_pasta")
+	assert.Nil(err)
+	assert.Equal([]byte("edited in the meantime\n"), b)
+	_, err = ds.Get("history")
+	assert.NotNil(err)
+}
+
+// Tests that Unblock archives lines that consist only of a blocker.
+func TestBasicTaskstore_Unblock_BlockerOnly(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	ts := NewBasicTaskstore(ds)
+	defer cleanup()
+
+	err := ts.Unblock(common.GetLineID("make_pasta", "\t[b cooked]"))
+	assert.Nil(err)
+
+	b, err := ds.Get("make_pasta")
+	assert.Nil(err)
+	assert.False(regexp.MustCompile("cooked").Match(b))
+
+	b, err = ds.Get("history")
+	assert.Nil(err)
+	assert.True(regexp.MustCompile(`\[archive make_pasta\] \t\[b cooked\]\n$`).Match(b))
+}
+
+// Tests that Unblock strips the blocker from lines that have other text.
+func TestBasicTaskstore_Unblock_WithText(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	ts := NewBasicTaskstore(ds)
+	defer cleanup()
+
+	err := ds.Put("sink", []byte("\tcall plumber\n\tbuy parts\nfix sink [w plumber] [b parts]\n"))
+	assert.Nil(err)
+
+	err = ts.Unblock(common.GetLineID("sink", "fix sink [w plumber] [b parts]"))
+	assert.Nil(err)
+
+	b, err := ds.Get("sink")
+	assert.Nil(err)
+	assert.Equal("\tcall plumber\n\tbuy parts\nfix sink\n", string(b))
+
+	evs := ts.Feed().Since("sink", 0, 0)
+	assert.Equal(1, len(evs))
+	assert.Equal("Unblock", evs[0].Op)
+	assert.Equal(common.GetLineID("sink", "fix sink"), evs[0].LineID)
+
+	// The rest of the line is left as it was
+	err = ds.Put("sink", []byte("[b parts] fix  the  sink\n"))
+	assert.Nil(err)
+	err = ts.Unblock(common.GetLineID("sink", "[b parts] fix  the  sink"))
+	assert.Nil(err)
+	b, err = ds.Get("sink")
+	assert.Nil(err)
+	assert.Equal("fix  the  sink\n", string(b))
 }
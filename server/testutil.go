@@ -59,6 +59,21 @@ func NewBasicTaskstoreWithTestdata() (*BasicTaskstore, func()) {
 	return NewBasicTaskstore(ds), cleanup
 }
 
+// racingDatastore is a Datastore that simulates somebody else editing a list file while we're in
+// the middle of modifying it.
+//
+// Whenever CompareAndPut is called, racingDatastore first overwrites the file with different data.
+type racingDatastore struct {
+	Datastore
+}
+
+func (ds *racingDatastore) CompareAndPut(name, stateId string, b []byte) error {
+	if err := ds.Datastore.Put(name, []byte("edited in the meantime\n")); err != nil {
+		return err
+	}
+	return ds.Datastore.CompareAndPut(name, stateId, b)
+}
+
 // ap is a singleton addrPool that we use to provision addrs for tests to listen on.
 var ap *addrPool
 
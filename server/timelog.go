@@ -0,0 +1,266 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// timelogName is the name of the Datastore file in which we record which frame is on top of each
+// list over time.
+const timelogName = "timelog"
+
+// TimeInterval is a span of time during which a node was the top frame of its list.
+type TimeInterval struct {
+	ListName string
+	// Path is the referents of the node and its ancestors, from the task's root node down to the
+	// node itself.
+	Path  []string
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of iv.
+func (iv TimeInterval) Duration() time.Duration {
+	return iv.End.Sub(iv.Start)
+}
+
+// nodePath returns the path (see TimeInterval) to n. If n is nil, nodePath returns an empty slice.
+func nodePath(n *common.TreeNode) []string {
+	path := make([]string, 0)
+	for ; n != nil; n = n.Parent {
+		path = append([]string{n.Referent}, path...)
+	}
+	return path
+}
+
+// topPath returns the path (see TimeInterval) to the top frame of taskList, as determined by
+// common.Top. If there's no top frame, topPath returns an empty slice.
+func topPath(taskList []*common.Task) []string {
+	return nodePath(common.Top(taskList))
+}
+
+// escapePath returns the elements of path escaped (see escapeReferent), so that they can be stored
+// as tab-separated fields of a line.
+func escapePath(path []string) []string {
+	escaped := make([]string, len(path))
+	for i := range path {
+		escaped[i] = escapeReferent(path[i])
+	}
+	return escaped
+}
+
+// unescapePath reverses escapePath.
+func unescapePath(escaped []string) []string {
+	path := make([]string, len(escaped))
+	for i := range escaped {
+		path[i] = unescapeReferent(escaped[i])
+	}
+	return path
+}
+
+// timelogLine returns a line for the timelog recording that, as of t, the top frame of the list
+// identified by listName is the one at path.
+//
+// Timelog lines consist of tab-separated fields: the time in RFC 3339 format, the list name, and
+// then the escaped elements of the path (see escapePath), if any. An empty path means the list has
+// no top frame.
+func timelogLine(t time.Time, listName string, path []string) []byte {
+	fields := append([]string{t.Format(time.RFC3339), listName}, escapePath(path)...)
+	return []byte(strings.Join(fields, "\t") + "\n")
+}
+
+// parseTimelogLine parses a line produced by timelogLine.
+func parseTimelogLine(line []byte) (time.Time, string, []string, error) {
+	fields := strings.Split(string(line), "\t")
+	if len(fields) < 2 {
+		return time.Time{}, "", nil, fmt.Errorf("malformed timelog line `%s`", string(line))
+	}
+	t, err := time.Parse(time.RFC3339, fields[0])
+	if err != nil {
+		return time.Time{}, "", nil, err
+	}
+	return t, fields[1], unescapePath(fields[2:]), nil
+}
+
+// pathsEqual determines whether a and b are the same path.
+func pathsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// lastTops returns the most recently recorded top frame path for each list in the timelog.
+//
+// The caller must hold ts.timelogMu.
+func (ts *BasicTaskstore) lastTops() (map[string][]string, error) {
+	if ts.tops != nil {
+		return ts.tops, nil
+	}
+
+	tops := make(map[string][]string)
+	b, err := ts.datastore.Get(timelogName)
+	if err != nil {
+		// No timelog yet
+		ts.tops = tops
+		return tops, nil
+	}
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		_, listName, path, err := parseTimelogLine(line)
+		if err != nil {
+			return nil, err
+		}
+		tops[listName] = path
+	}
+	ts.tops = tops
+	return tops, nil
+}
+
+// trackTop records in the timelog the top frame of taskList, the list identified by listName, if
+// it has changed since we last recorded it.
+//
+// trackTop is called after every change to a list (see observe), so that the timelog reflects every
+// push, pop, archive, and so on.
+func (ts *BasicTaskstore) trackTop(listName string, taskList []*common.Task) error {
+	path := topPath(taskList)
+
+	ts.timelogMu.Lock()
+	defer ts.timelogMu.Unlock()
+	tops, err := ts.lastTops()
+	if err != nil {
+		return err
+	}
+	if prev, ok := tops[listName]; ok && pathsEqual(prev, path) {
+		return nil
+	}
+
+	if err := ts.datastore.Append(timelogName, timelogLine(time.Now(), listName, path)); err != nil {
+		return err
+	}
+	tops[listName] = path
+	return nil
+}
+
+// GetTimeIntervals returns the intervals during which nodes have been the top frame of their
+// lists, in chronological order of their start times.
+//
+// A node that's on top right now has an interval ending at the current time.
+func (ts *BasicTaskstore) GetTimeIntervals() ([]TimeInterval, error) {
+	ts.timelogMu.Lock()
+	defer ts.timelogMu.Unlock()
+
+	rslt := make([]TimeInterval, 0)
+	b, err := ts.datastore.Get(timelogName)
+	if err != nil {
+		// No timelog yet
+		return rslt, nil
+	}
+
+	// open holds the index in rslt of the interval that is still open for each list.
+	open := make(map[string]int)
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		t, listName, path, err := parseTimelogLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		if i, ok := open[listName]; ok {
+			rslt[i].End = t
+			delete(open, listName)
+		}
+		if len(path) > 0 {
+			rslt = append(rslt, TimeInterval{ListName: listName, Path: path, Start: t})
+			open[listName] = len(rslt) - 1
+		}
+	}
+
+	now := time.Now()
+	for _, i := range open {
+		rslt[i].End = now
+	}
+	return rslt, nil
+}
+
+// TimeReportEntry is a line in a time report: the total time spent on whatever Key identifies.
+type TimeReportEntry struct {
+	Key      string
+	Duration time.Duration
+}
+
+// sortedEntries turns totals into a slice of TimeReportEntry, sorted by key.
+func sortedEntries(totals map[string]time.Duration) []TimeReportEntry {
+	rslt := make([]TimeReportEntry, 0, len(totals))
+	for k, d := range totals {
+		rslt = append(rslt, TimeReportEntry{Key: k, Duration: d})
+	}
+	sort.Slice(rslt, func(i, j int) bool { return rslt[i].Key < rslt[j].Key })
+	return rslt
+}
+
+// TimeByNode totals the time spent on each node in intervals.
+//
+// Time is rolled up: the time spent on a node includes the time spent on all of its descendants.
+// So, for example, time spent on `put water in pot` counts toward `boil water` and `make pasta` as
+// well.
+//
+// Each key is the list name and the node's path, like "make_pasta: make pasta > boil water".
+func TimeByNode(intervals []TimeInterval) []TimeReportEntry {
+	totals := make(map[string]time.Duration)
+	for _, iv := range intervals {
+		for i := range iv.Path {
+			k := fmt.Sprintf("%s: %s", iv.ListName, strings.Join(iv.Path[:i+1], " > "))
+			totals[k] += iv.Duration()
+		}
+	}
+	return sortedEntries(totals)
+}
+
+// TimeByTask totals the time spent on each task (that is, each root node) in intervals.
+//
+// Each key is the list name and the task's referent, like "make_pasta: make pasta".
+func TimeByTask(intervals []TimeInterval) []TimeReportEntry {
+	totals := make(map[string]time.Duration)
+	for _, iv := range intervals {
+		k := fmt.Sprintf("%s: %s", iv.ListName, iv.Path[0])
+		totals[k] += iv.Duration()
+	}
+	return sortedEntries(totals)
+}
+
+// TimeByDay totals the time spent on each day in intervals, in loc's time zone.
+//
+// Intervals that span midnight are split between the days they span. Each key is a date, like
+// "2021-12-30".
+func TimeByDay(intervals []TimeInterval, loc *time.Location) []TimeReportEntry {
+	totals := make(map[string]time.Duration)
+	for _, iv := range intervals {
+		start := iv.Start.In(loc)
+		end := iv.End.In(loc)
+		for start.Before(end) {
+			y, m, d := start.Date()
+			midnight := time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+			if midnight.After(end) {
+				midnight = end
+			}
+			totals[start.Format("2006-01-02")] += midnight.Sub(start)
+			start = midnight
+		}
+	}
+	return sortedEntries(totals)
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests that the timelog records each change of top frame.
+func TestBasicTaskstore_GetTimeIntervals(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	intervals, err := ts.GetTimeIntervals()
+	assert.Nil(err)
+	assert.Equal(0, len(intervals))
+
+	// pop the top frame
+	err = ts.ArchiveLine(common.GetLineID("make_pasta", "\t\tput water in pot"))
+	assert.Nil(err)
+	// push a frame
+	err = ts.InsertTask(common.LineID("make_pasta:0"), common.NewTask(common.NewTreeNode("check Twitter")))
+	assert.Nil(err)
+	// a change that doesn't affect the top frame
+	err = ts.ArchiveLine(common.GetLineID("make_pasta", "\tdrain pasta"))
+	assert.Nil(err)
+
+	intervals, err = ts.GetTimeIntervals()
+	assert.Nil(err)
+	assert.Equal(2, len(intervals))
+	assert.Equal("make_pasta", intervals[0].ListName)
+	assert.Equal([]string{"make pasta", "boil water", "put pot on burner"}, intervals[0].Path)
+	assert.Equal(intervals[0].End, intervals[1].Start)
+	assert.Equal([]string{"check Twitter"}, intervals[1].Path)
+	assert.False(intervals[1].End.Before(intervals[1].Start))
+
+	// a fresh taskstore picks up where the old one left off
+	ts = NewBasicTaskstore(ds)
+	err = ts.ArchiveLine(common.GetLineID("make_pasta", "\tput pasta in water"))
+	assert.Nil(err)
+	intervals, err = ts.GetTimeIntervals()
+	assert.Nil(err)
+	assert.Equal(2, len(intervals))
+}
+
+// Tests that referents containing tabs and newlines survive the trip through the timelog.
+func TestBasicTaskstore_GetTimeIntervals_Escaping(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+	ts := NewBasicTaskstore(ds)
+
+	referent := "buy:\n- eggs\t(6)\n- flour"
+	err := ts.InsertTask(common.LineID("make_pasta:0"), common.NewTask(common.NewTreeNode(referent)))
+	assert.Nil(err)
+	err = ts.ArchiveLine(common.GetLineID("make_pasta", `buy:\n- eggs\t(6)\n- flour`))
+	assert.Nil(err)
+
+	intervals, err := ts.GetTimeIntervals()
+	assert.Nil(err)
+	if assert.Equal(2, len(intervals)) {
+		assert.Equal([]string{referent}, intervals[0].Path)
+	}
+
+	// stats and interrupts skip malformed timelog lines
+	err = ds.Append(timelogName, []byte("garbage\n"))
+	assert.Nil(err)
+	_, err = ts.Stats(time.Now().Add(-time.Hour))
+	assert.Nil(err)
+	_, err = ts.Interrupts()
+	assert.Nil(err)
+}
+
+// timeIntervals returns some TimeIntervals for testing reports.
+func timeIntervals() []TimeInterval {
+	t0 := time.Date(2021, 12, 30, 23, 0, 0, 0, time.UTC)
+	return []TimeInterval{
+		TimeInterval{
+			ListName: "make_pasta",
+			Path:     []string{"make pasta", "boil water", "put water in pot"},
+			Start:    t0,
+			End:      t0.Add(10 * time.Minute),
+		},
+		TimeInterval{
+			ListName: "make_pasta",
+			Path:     []string{"make pasta", "boil water", "turn burner on"},
+			Start:    t0.Add(10 * time.Minute),
+			End:      t0.Add(15 * time.Minute),
+		},
+		TimeInterval{
+			ListName: "make_pasta",
+			Path:     []string{"make pasta", "put pasta in water"},
+			Start:    t0.Add(50 * time.Minute),
+			End:      t0.Add(90 * time.Minute),
+		},
+		TimeInterval{
+			ListName: "pers",
+			Path:     []string{"check Twitter"},
+			Start:    t0.Add(15 * time.Minute),
+			End:      t0.Add(50 * time.Minute),
+		},
+	}
+}
+
+func TestTimeByNode(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	assert.Equal(
+		[]TimeReportEntry{
+			TimeReportEntry{Key: "make_pasta: make pasta", Duration: 55 * time.Minute},
+			TimeReportEntry{Key: "make_pasta: make pasta > boil water", Duration: 15 * time.Minute},
+			TimeReportEntry{Key: "make_pasta: make pasta > boil water > put water in pot", Duration: 10 * time.Minute},
+			TimeReportEntry{Key: "make_pasta: make pasta > boil water > turn burner on", Duration: 5 * time.Minute},
+			TimeReportEntry{Key: "make_pasta: make pasta > put pasta in water", Duration: 40 * time.Minute},
+			TimeReportEntry{Key: "pers: check Twitter", Duration: 35 * time.Minute},
+		},
+		TimeByNode(timeIntervals()),
+	)
+}
+
+func TestTimeByTask(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	assert.Equal(
+		[]TimeReportEntry{
+			TimeReportEntry{Key: "make_pasta: make pasta", Duration: 55 * time.Minute},
+			TimeReportEntry{Key: "pers: check Twitter", Duration: 35 * time.Minute},
+		},
+		TimeByTask(timeIntervals()),
+	)
+}
+
+func TestTimeByDay(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	assert.Equal(
+		[]TimeReportEntry{
+			TimeReportEntry{Key: "2021-12-30", Duration: 60 * time.Minute},
+			TimeReportEntry{Key: "2021-12-31", Duration: 30 * time.Minute},
+		},
+		TimeByDay(timeIntervals(), time.UTC),
+	)
+}
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+)
+
+// touchedName is the name of the Datastore file in which the time each node was last touched is
+// stored.
+const touchedName = "touched"
+
+// lastTouched returns the time each node was last touched, by line ID.
+//
+// A node is touched when its line first appears (because it's been pushed, say, or moved, or
+// edited so that its ID changed), when its note is changed, and when it's touched explicitly (see
+// Touch). Nodes that were already there when the Taskstore first saw their list are considered to
+// have been touched then.
+//
+// The touched file consists of lines of tab-separated fields: a line ID and the time in RFC 3339
+// format.
+//
+// The caller must hold ts.touchedMu.
+func (ts *BasicTaskstore) lastTouched() (map[common.LineID]time.Time, error) {
+	if ts.touched != nil {
+		return ts.touched, nil
+	}
+
+	touched := make(map[common.LineID]time.Time)
+	b, err := ts.datastore.Get(touchedName)
+	if err != nil {
+		// No touched file yet
+		ts.touched = touched
+		return touched, nil
+	}
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		fields := strings.Split(string(line), "\t")
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("malformed touched line `%s`", string(line))
+		}
+		t, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil {
+			return nil, err
+		}
+		touched[common.LineID(fields[0])] = t
+	}
+	ts.touched = touched
+	return touched, nil
+}
+
+// writeTouched writes the last-touched times to the Datastore.
+//
+// The caller must hold ts.touchedMu.
+func (ts *BasicTaskstore) writeTouched(touched map[common.LineID]time.Time) error {
+	ids := make([]string, 0, len(touched))
+	for id := range touched {
+		ids = append(ids, string(id))
+	}
+	sort.Strings(ids)
+
+	var b bytes.Buffer
+	for _, id := range ids {
+		fmt.Fprintf(&b, "%s\t%s\n", id, touched[common.LineID(id)].Format(time.RFC3339))
+	}
+	return ts.datastore.Put(touchedName, b.Bytes())
+}
+
+// trackTouched updates the last-touched times of the nodes in taskList, the list identified by
+// listName, after a change to the list. Nodes that are new to the list are touched, as is the node
+// identified by lineId (if it's not empty), and nodes that are gone are forgotten.
+func (ts *BasicTaskstore) trackTouched(listName string, taskList []*common.Task, lineId common.LineID) error {
+	ts.touchedMu.Lock()
+	defer ts.touchedMu.Unlock()
+	touched, err := ts.lastTouched()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Truncate(time.Second)
+	current := make(map[common.LineID]bool)
+	changed := false
+	for _, t := range taskList {
+		t.RootNode.Walk(func(n *common.TreeNode) error {
+			id := ts.nodeLineId(listName, n)
+			current[id] = true
+			if _, ok := touched[id]; !ok || id == lineId {
+				touched[id] = now
+				changed = true
+			}
+			return nil
+		})
+	}
+	prefix := listName + ":"
+	for id := range touched {
+		if strings.HasPrefix(string(id), prefix) && !current[id] {
+			delete(touched, id)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return ts.writeTouched(touched)
+}
+
+// Touch marks the node identified by lineId as touched now, e.g. when the user has reviewed it and
+// decided to keep it.
+func (ts *BasicTaskstore) Touch(lineId common.LineID) error {
+	listName, _, err := ts.splitLineId(lineId)
+	if err != nil {
+		return err
+	}
+	taskList, err := ts.GetList(listName)
+	if err != nil {
+		return err
+	}
+	if ts.findNode(listName, taskList, lineId) == nil {
+		return fmt.Errorf("no node with ID `%s`", string(lineId))
+	}
+	return ts.trackTouched(listName, taskList, lineId)
+}
+
+// StaleNode is a node that hasn't been touched in a while (see StaleNodes).
+type StaleNode struct {
+	ListName string
+	LineID   common.LineID
+	// Path is the path (see TimeInterval) to the node.
+	Path []string
+	// LastTouched is the last time the node or any of its descendants was touched.
+	LastTouched time.Time
+}
+
+// StaleNodes returns the nodes in the list identified by listName (or in all lists, if listName is
+// empty) that haven't been touched, and none of whose descendants have been touched, for at least
+// age. Nodes whose parents are stale aren't included, since reviewing the parent covers them.
+//
+// The nodes are sorted by when they were last touched, oldest first. Lists that can't be parsed are
+// skipped.
+func (ts *BasicTaskstore) StaleNodes(listName string, age time.Duration) ([]StaleNode, error) {
+	names := []string{listName}
+	if listName == "" {
+		var err error
+		if names, err = ts.ListNames(); err != nil {
+			return nil, err
+		}
+	}
+
+	rslt := make([]StaleNode, 0)
+	cutoff := time.Now().Add(-age)
+	for _, name := range names {
+		taskList, err := ts.GetList(name)
+		if err != nil {
+			if listName == "" {
+				continue
+			}
+			return nil, err
+		}
+		// Make sure every node has a last-touched time, in case we haven't seen the list before.
+		if err := ts.trackTouched(name, taskList, ""); err != nil {
+			return nil, err
+		}
+
+		ts.touchedMu.Lock()
+		touched, err := ts.lastTouched()
+		if err != nil {
+			ts.touchedMu.Unlock()
+			return nil, err
+		}
+		// latest returns the last time n or any of its descendants was touched.
+		var latest func(n *common.TreeNode) time.Time
+		latest = func(n *common.TreeNode) time.Time {
+			t := touched[ts.nodeLineId(name, n)]
+			for _, ch := range n.Children {
+				if chT := latest(ch); chT.After(t) {
+					t = chT
+				}
+			}
+			return t
+		}
+		for _, task := range taskList {
+			task.RootNode.Walk(func(n *common.TreeNode) error {
+				t := latest(n)
+				if t.After(cutoff) {
+					return nil
+				}
+				rslt = append(rslt, StaleNode{
+					ListName:    name,
+					LineID:      ts.nodeLineId(name, n),
+					Path:        nodePath(n),
+					LastTouched: t,
+				})
+				return common.SkipSubtree
+			})
+		}
+		ts.touchedMu.Unlock()
+	}
+
+	sort.SliceStable(rslt, func(i, j int) bool { return rslt[i].LastTouched.Before(rslt[j].LastTouched) })
+	return rslt, nil
+}
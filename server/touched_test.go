@@ -0,0 +1,89 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danslimmon/impulse/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// stalePaths returns the paths of nodes, joined for easy comparison.
+func stalePaths(nodes []StaleNode) []string {
+	rslt := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		rslt = append(rslt, strings.Join(n.Path, " > "))
+	}
+	return rslt
+}
+
+func TestBasicTaskstore_StaleNodes(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ds, cleanup := newFSDatastoreWithTestdata()
+	defer cleanup()
+
+	// Every line of make_pasta was last touched a month ago.
+	b, err := ds.Get("make_pasta")
+	assert.Nil(err)
+	monthAgo := time.Now().AddDate(0, -1, 0).Format(time.RFC3339)
+	var touched strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(string(b), "\n"), "\n") {
+		fmt.Fprintf(&touched, "%s\t%s\n", common.GetLineID("make_pasta", line), monthAgo)
+	}
+	err = ds.Put("touched", []byte(touched.String()))
+	assert.Nil(err)
+	ts := NewBasicTaskstore(ds)
+
+	// Only the topmost stale node is listed. The other lists haven't been seen before, so they're
+	// considered touched now.
+	stale, err := ts.StaleNodes("", 14*24*time.Hour)
+	assert.Nil(err)
+	assert.Equal([]string{"make pasta"}, stalePaths(stale))
+	assert.Equal(common.GetLineID("make_pasta", "make pasta"), stale[0].LineID)
+	assert.Equal(monthAgo, stale[0].LastTouched.Format(time.RFC3339))
+	stale, err = ts.StaleNodes("multiple_nested", 0)
+	assert.Nil(err)
+	assert.Equal([]string{"task 0", "task 1"}, stalePaths(stale))
+
+	// Touching a node freshens its ancestors too, but not its siblings
+	err = ts.Touch(common.GetLineID("make_pasta", "\t\tput water in pot"))
+	assert.Nil(err)
+	stale, err = ts.StaleNodes("make_pasta", 14*24*time.Hour)
+	assert.Nil(err)
+	assert.Equal([]string{
+		"make pasta > boil water > put pot on burner",
+		"make pasta > boil water > turn burner on",
+		"make pasta > put pasta in water",
+		"make pasta > [b cooked]",
+		"make pasta > drain pasta",
+	}, stalePaths(stale))
+
+	// So does any mutation
+	err = ts.SetNote(common.GetLineID("make_pasta", "\tdrain pasta"), "colander's in the cupboard")
+	assert.Nil(err)
+	_, err = ts.Snooze(common.GetLineID("make_pasta", "\t[b cooked]"), time.Now().Add(time.Hour))
+	assert.Nil(err)
+	stale, err = ts.StaleNodes("make_pasta", 14*24*time.Hour)
+	assert.Nil(err)
+	assert.Equal([]string{
+		"make pasta > boil water > put pot on burner",
+		"make pasta > boil water > turn burner on",
+		"make pasta > put pasta in water",
+	}, stalePaths(stale))
+
+	// Archived nodes are forgotten
+	err = ts.ArchiveLine(common.GetLineID("make_pasta", "\tput pasta in water"))
+	assert.Nil(err)
+	b, err = ds.Get("touched")
+	assert.Nil(err)
+	assert.NotContains(string(b), string(common.GetLineID("make_pasta", "\tput pasta in water")))
+
+	err = ts.Touch(common.GetLineID("make_pasta", "nonexistent"))
+	assert.NotNil(err)
+	_, err = ts.StaleNodes("nonexistent", 0)
+	assert.NotNil(err)
+}